@@ -3,6 +3,7 @@ package duckdb
 import (
 	"database/sql/driver"
 	"errors"
+	"time"
 
 	"github.com/marcboeker/go-duckdb/mapping"
 )
@@ -23,6 +24,11 @@ type Appender struct {
 	types []mapping.LogicalType
 	// The number of appended rows.
 	rowCount int
+
+	// The qualified name of the table to append to. It is empty for query appenders.
+	table string
+	// The number of rows appended since the last flush.
+	unflushedRows int
 }
 
 // NewAppenderFromConn returns a new Appender for the default catalog.
@@ -47,6 +53,7 @@ func NewAppender(driverConn driver.Conn, catalog, schema, table string) (*Append
 	if err != nil {
 		return nil, err
 	}
+	a.table = qualifiedTableName(catalog, schema, table)
 
 	state := mapping.AppenderCreateExt(a.conn.conn, catalog, schema, table, &a.appender)
 	if state == mapping.StateError {
@@ -124,6 +131,14 @@ func NewQueryAppender(driverConn driver.Conn, query, table string, colTypes []Ty
 // Does not close the appender, even if it returns an error. Unless you have a good reason to call this,
 // call Close when you are done with the appender.
 func (a *Appender) Flush() error {
+	start := time.Now()
+	err := a.flush()
+	a.afterFlush(start, err)
+
+	return err
+}
+
+func (a *Appender) flush() error {
 	if err := a.appendDataChunk(); err != nil {
 		return getError(errAppenderFlush, invalidatedAppenderError(err))
 	}
@@ -143,6 +158,7 @@ func (a *Appender) Close() error {
 		return getError(errAppenderDoubleClose, nil)
 	}
 	a.closed = true
+	start := time.Now()
 
 	// Append all remaining chunks.
 	errAppend := a.appendDataChunk()
@@ -163,10 +179,11 @@ func (a *Appender) Close() error {
 
 	err := errors.Join(errAppend, errFlush, errClose)
 	if err != nil {
-		return getError(invalidatedAppenderError(err), nil)
+		err = getError(invalidatedAppenderError(err), nil)
 	}
+	a.afterFlush(start, err)
 
-	return nil
+	return err
 }
 
 // AppendRow loads a row of values into the appender. The values are provided as separate arguments.
@@ -228,10 +245,44 @@ func (a *Appender) appendRowSlice(args []driver.Value) error {
 		}
 	}
 	a.rowCount++
+	a.unflushedRows++
 
 	return nil
 }
 
+// afterFlush records the statistics of a flush and calls the OnAppenderFlush hooks of the connector.
+func (a *Appender) afterFlush(start time.Time, err error) {
+	if a.conn.txLog != nil && a.unflushedRows > 0 {
+		a.conn.txLog.appended = true
+	}
+	if err != nil {
+		a.conn.connector.stats.recordError(err)
+	} else {
+		a.conn.connector.stats.recordAppenderFlush(a.unflushedRows)
+	}
+	if len(a.conn.connector.hooks) != 0 {
+		a.conn.connector.onAppenderFlush(AppenderFlushInfo{
+			Table:    a.table,
+			Rows:     a.unflushedRows,
+			Duration: time.Since(start),
+			Err:      err,
+			ConnId:   a.conn.id,
+		})
+	}
+	a.unflushedRows = 0
+}
+
+func qualifiedTableName(catalog, schema, table string) string {
+	name := table
+	if schema != "" {
+		name = schema + "." + name
+	}
+	if catalog != "" {
+		name = catalog + "." + name
+	}
+	return name
+}
+
 func (a *Appender) appendDataChunk() error {
 	if a.rowCount == 0 {
 		// Nothing to append.
@@ -208,6 +208,7 @@ func (r *arrowStreamReader) Err() error {
 
 // QueryContext prepares statements, executes them, returns Apache Arrow array.RecordReader as a result of the last
 // executed statement. Arguments are bound to the last statement.
+// Use QueryResultSets to get the result of each statement.
 func (a *Arrow) QueryContext(ctx context.Context, query string, args ...any) (array.RecordReader, error) {
 	if a.conn.closed {
 		return nil, errClosedCon
@@ -224,7 +225,7 @@ func (a *Arrow) QueryContext(ctx context.Context, query string, args ...any) (ar
 
 	// Execute all statements without args, except the last one.
 	for i := mapping.IdxT(0); i < size-mapping.IdxT(1); i++ {
-		extractedStmt, err := a.conn.prepareExtractedStmt(*stmts, i)
+		extractedStmt, err := a.conn.prepareExtractedStmt(*stmts, i, query)
 		if err != nil {
 			return nil, err
 		}
@@ -238,13 +239,55 @@ func (a *Arrow) QueryContext(ctx context.Context, query string, args ...any) (ar
 	}
 
 	// Prepare and execute the last statement with args.
-	stmt, err := a.conn.prepareExtractedStmt(*stmts, size-mapping.IdxT(1))
+	return a.query(ctx, *stmts, size-mapping.IdxT(1), query, a.anyArgsToNamedArgs(args))
+}
+
+// QueryResultSets prepares and executes the statements of a query,
+// and returns an Apache Arrow array.RecordReader per statement, in order.
+// Arguments are bound to the last statement.
+// If a statement fails, QueryResultSets releases the readers of the previous statements.
+func (a *Arrow) QueryResultSets(ctx context.Context, query string, args ...any) ([]array.RecordReader, error) {
+	if a.conn.closed {
+		return nil, errClosedCon
+	}
+
+	cleanupCtx := a.conn.setContext(ctx)
+	defer cleanupCtx()
+
+	stmts, size, errExtract := a.conn.extractStmts(query)
+	if errExtract != nil {
+		return nil, errExtract
+	}
+	defer mapping.DestroyExtracted(stmts)
+
+	readers := make([]array.RecordReader, 0, size)
+	for i := mapping.IdxT(0); i < size; i++ {
+		var stmtArgs []driver.NamedValue
+		if i == size-mapping.IdxT(1) {
+			stmtArgs = a.anyArgsToNamedArgs(args)
+		}
+
+		reader, err := a.query(ctx, *stmts, i, query, stmtArgs)
+		if err != nil {
+			for _, r := range readers {
+				r.Release()
+			}
+			return nil, err
+		}
+		readers = append(readers, reader)
+	}
+	return readers, nil
+}
+
+// query prepares and executes the i-th extracted statement, and returns its result.
+func (a *Arrow) query(ctx context.Context, stmts mapping.ExtractedStatements, i mapping.IdxT, query string, args []driver.NamedValue) (array.RecordReader, error) {
+	stmt, err := a.conn.prepareExtractedStmt(stmts, i, query)
 	if err != nil {
 		return nil, err
 	}
 	defer stmt.Close()
 
-	res, err := a.execute(stmt, a.anyArgsToNamedArgs(args))
+	res, err := a.execute(stmt, args)
 	if err != nil {
 		return nil, err
 	}
@@ -95,6 +95,38 @@ func TestArrow(t *testing.T) {
 		require.NoError(t, err)
 	})
 
+	t.Run("query result sets", func(t *testing.T) {
+		c := newConnectorWrapper(t, ``, nil)
+		defer closeConnectorWrapper(t, c)
+
+		innerConn := openDriverConnWrapper(t, c)
+		defer closeDriverConnWrapper(t, &innerConn)
+
+		ar, err := NewArrowFromConn(innerConn)
+		require.NoError(t, err)
+
+		rdrs, err := ar.QueryResultSets(context.Background(), `SELECT 1 AS a; SELECT 'x' AS b, ? AS c`, 2)
+		require.NoError(t, err)
+		require.Len(t, rdrs, 2)
+
+		require.Equal(t, "a", rdrs[0].Schema().Field(0).Name)
+		require.True(t, rdrs[0].Next())
+		require.Equal(t, "1", rdrs[0].Record().Column(0).ValueStr(0))
+
+		require.Equal(t, 2, rdrs[1].Schema().NumFields())
+		require.True(t, rdrs[1].Next())
+		require.Equal(t, "x", rdrs[1].Record().Column(0).ValueStr(0))
+		require.Equal(t, "2", rdrs[1].Record().Column(1).ValueStr(0))
+
+		for _, rdr := range rdrs {
+			require.NoError(t, rdr.Err())
+			rdr.Release()
+		}
+
+		_, err = ar.QueryResultSets(context.Background(), `SELECT 1; SELECT * FROM missing`)
+		require.ErrorContains(t, err, "missing")
+	})
+
 	t.Run("query error", func(t *testing.T) {
 		err := conn.Raw(func(driverConn any) error {
 			innerConn, ok := driverConn.(driver.Conn)
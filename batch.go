@@ -0,0 +1,176 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// BatchError is the error of a failed argument set of a batch.
+type BatchError struct {
+	// Index is the index of the first failing argument set.
+	Index int
+	// Err is the error of the argument set.
+	Err error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("argument set %d: %s", e.Index, e.Err.Error())
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// ExecBatch executes the statement once per argument set, and returns the number of rows affected by each execution.
+// It executes the batch in a single transaction, which it commits after the last argument set,
+// or rolls back on the first failing argument set. If the connection has an open transaction,
+// then ExecBatch executes the batch in that transaction, without committing or rolling it back.
+// On failure, it returns a *BatchError containing the index of the failing argument set,
+// and the number of rows affected by the argument sets before it.
+func (s *Stmt) ExecBatch(ctx context.Context, argSets [][]driver.NamedValue) ([]int64, error) {
+	return s.execBatch(ctx, len(argSets), func(i int) ([]driver.NamedValue, error) {
+		return argSets[i], nil
+	})
+}
+
+// ExecBatchColumns is the columnar variant of ExecBatch.
+// It expects a slice per parameter of the statement, e.g., a []int64 and a []string,
+// and executes the statement once per index of the slices, which must have equal lengths.
+func (s *Stmt) ExecBatchColumns(ctx context.Context, columns ...any) ([]int64, error) {
+	values := make([]reflect.Value, len(columns))
+	for i, column := range columns {
+		values[i] = reflect.ValueOf(column)
+		if values[i].Kind() != reflect.Slice {
+			return nil, errors.Join(errBatch, addIndexToError(errBatchColumnNotSlice, i))
+		}
+		if values[i].Len() != values[0].Len() {
+			return nil, errors.Join(errBatch, addIndexToError(errBatchColumnLength, i))
+		}
+	}
+
+	n := 0
+	if len(values) != 0 {
+		n = values[0].Len()
+	}
+	return s.execBatch(ctx, n, func(i int) ([]driver.NamedValue, error) {
+		args := make([]driver.NamedValue, len(values))
+		for j, column := range values {
+			args[j] = driver.NamedValue{Ordinal: j + 1, Value: column.Index(i).Interface()}
+			if err := s.conn.convertArg(&args[j]); err != nil {
+				return nil, err
+			}
+		}
+		return args, nil
+	})
+}
+
+// execBatch executes the statement with n argument sets in a transaction.
+func (s *Stmt) execBatch(ctx context.Context, n int, argsFn func(i int) ([]driver.NamedValue, error)) ([]int64, error) {
+	if s.closed {
+		return nil, errClosedStmt
+	}
+	if s.rows {
+		return nil, errActiveRows
+	}
+	// Do not begin a transaction with a done context,
+	// as interrupting the idle connection would interrupt its next statement, e.g., the rollback.
+	if err := ctx.Err(); err != nil {
+		return nil, &BatchError{Index: 0, Err: err}
+	}
+
+	// Begin a transaction, unless the connection has an open transaction.
+	var tx driver.Tx
+	if !s.conn.tx {
+		var err error
+		if tx, err = s.conn.BeginTx(ctx, driver.TxOptions{}); err != nil {
+			return nil, err
+		}
+	}
+
+	rowsAffected, err := s.execBatchRows(ctx, n, argsFn)
+	if tx == nil {
+		return rowsAffected, err
+	}
+	if err != nil {
+		return rowsAffected, errors.Join(err, tx.Rollback())
+	}
+	return rowsAffected, tx.Commit()
+}
+
+func (s *Stmt) execBatchRows(ctx context.Context, n int, argsFn func(i int) ([]driver.NamedValue, error)) ([]int64, error) {
+	if err := s.conn.connector.beginStmt(false); err != nil {
+		return nil, err
+	}
+	defer s.conn.connector.endStmt()
+
+	// Interrupt the batch, if the context is done.
+	// Wait for the interrupt to finish, so that it cannot interrupt a later statement.
+	interruptedCh := make(chan struct{})
+	stop := context.AfterFunc(ctx, func() {
+		mapping.Interrupt(s.conn.conn)
+		close(interruptedCh)
+	})
+	defer func() {
+		if !stop() {
+			<-interruptedCh
+		}
+	}()
+
+	stmtType := StmtType(mapping.PreparedStatementType(*s.preparedStmt))
+	rowsAffected := make([]int64, 0, n)
+	for i := range n {
+		if err := ctx.Err(); err != nil {
+			return rowsAffected, &BatchError{Index: i, Err: err}
+		}
+
+		args, err := argsFn(i)
+		if err == nil {
+			err = s.bind(args)
+		}
+		if err != nil {
+			return rowsAffected, &BatchError{Index: i, Err: err}
+		}
+
+		ra, err := s.execBatchRow(ctx, stmtType)
+		if err != nil {
+			return rowsAffected, &BatchError{Index: i, Err: err}
+		}
+		rowsAffected = append(rowsAffected, ra)
+	}
+	return rowsAffected, nil
+}
+
+// execBatchRow executes the bound statement, which execBatchRows registered.
+// Unless the connector has hooks, it executes the statement without a pending result.
+func (s *Stmt) execBatchRow(ctx context.Context, stmtType StmtType) (int64, error) {
+	if len(s.conn.connector.hooks) != 0 {
+		info := s.stmtInfo(nil)
+		res, err := s.executeWithHooks(ctx, &info, func(ctx context.Context) (*mapping.Result, error) {
+			return s.executeRegistered(ctx, stmtType)
+		})
+		if err != nil {
+			return 0, err
+		}
+		defer mapping.DestroyResult(res)
+		return int64(mapping.RowsChanged(res)), nil
+	}
+
+	var res mapping.Result
+	defer mapping.DestroyResult(&res)
+	if mapping.ExecutePrepared(*s.preparedStmt, &res) == mapping.StateError {
+		err := errors.Join(ctx.Err(), getDuckDBError(mapping.ResultError(&res)))
+		s.conn.recordStmt(stmtType, err)
+		return 0, err
+	}
+
+	s.conn.recordStmt(stmtType, nil)
+	if s.conn.txLog != nil {
+		s.conn.txLog.add(s, stmtType, int64(mapping.RowsChanged(&res)))
+	}
+	return int64(mapping.RowsChanged(&res)), nil
+}
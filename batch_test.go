@@ -0,0 +1,105 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecBatch(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (k INTEGER PRIMARY KEY, v VARCHAR)`)
+
+	driverConn, err := c.Connect(context.Background())
+	require.NoError(t, err)
+	defer closeDriverConnWrapper(t, &driverConn)
+	conn := driverConn.(*Conn)
+
+	prepare := func(query string) *Stmt {
+		stmt, err := conn.PrepareContext(context.Background(), query)
+		require.NoError(t, err)
+		return stmt.(*Stmt)
+	}
+	count := func() int {
+		var n int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM t`).Scan(&n))
+		return n
+	}
+
+	insert := prepare(`INSERT INTO t VALUES (?, ?)`)
+	defer func() {
+		require.NoError(t, insert.Close())
+	}()
+
+	t.Run("rows", func(t *testing.T) {
+		rowsAffected, err := insert.ExecBatch(context.Background(), [][]driver.NamedValue{
+			{{Ordinal: 1, Value: int64(1)}, {Ordinal: 2, Value: "a"}},
+			{{Ordinal: 1, Value: int64(2)}, {Ordinal: 2, Value: "b"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 1}, rowsAffected)
+		require.Equal(t, 2, count())
+
+		update := prepare(`UPDATE t SET v = $v WHERE k <= $k`)
+		defer func() {
+			require.NoError(t, update.Close())
+		}()
+		rowsAffected, err = update.ExecBatch(context.Background(), [][]driver.NamedValue{
+			{{Name: "k", Value: int64(2)}, {Name: "v", Value: "x"}},
+			{{Name: "k", Value: int64(0)}, {Name: "v", Value: "y"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int64{2, 0}, rowsAffected)
+	})
+
+	t.Run("columns", func(t *testing.T) {
+		keys := make([]int, 1000)
+		values := make([]string, 1000)
+		for i := range keys {
+			keys[i] = 100 + i
+			values[i] = "v"
+		}
+		rowsAffected, err := insert.ExecBatchColumns(context.Background(), keys, values)
+		require.NoError(t, err)
+		require.Len(t, rowsAffected, 1000)
+		require.Equal(t, 1002, count())
+
+		_, err = insert.ExecBatchColumns(context.Background(), keys, values[:1])
+		require.ErrorIs(t, err, errBatchColumnLength)
+		_, err = insert.ExecBatchColumns(context.Background(), keys, "v")
+		require.ErrorIs(t, err, errBatchColumnNotSlice)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		// The batch rolls back on the first failing argument set.
+		rowsAffected, err := insert.ExecBatchColumns(context.Background(), []int{3, 1, 4}, []string{"c", "a", "d"})
+		var batchErr *BatchError
+		require.True(t, errors.As(err, &batchErr))
+		require.Equal(t, 1, batchErr.Index)
+		require.ErrorContains(t, err, "primary key")
+		require.Equal(t, []int64{1}, rowsAffected)
+		require.Equal(t, 1002, count())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = insert.ExecBatchColumns(ctx, []int{3}, []string{"c"})
+		require.ErrorIs(t, err, context.Canceled)
+		require.Equal(t, 1002, count())
+	})
+
+	t.Run("transaction", func(t *testing.T) {
+		// The batch runs in the open transaction of the connection.
+		tx, err := conn.BeginTx(context.Background(), driver.TxOptions{})
+		require.NoError(t, err)
+		_, err = insert.ExecBatchColumns(context.Background(), []int{3}, []string{"c"})
+		require.NoError(t, err)
+		require.NoError(t, tx.Rollback())
+		require.Equal(t, 1002, count())
+	})
+}
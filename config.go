@@ -0,0 +1,286 @@
+package duckdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// AccessMode is the access mode of a DuckDB database.
+type AccessMode string
+
+const (
+	AccessModeAutomatic = AccessMode("automatic")
+	AccessModeReadOnly  = AccessMode("read_only")
+	AccessModeReadWrite = AccessMode("read_write")
+)
+
+// Order is the default order of ORDER BY clauses without an explicit direction.
+type Order string
+
+const (
+	OrderAsc  = Order("asc")
+	OrderDesc = Order("desc")
+)
+
+// Config contains the typed global configuration options of a DuckDB database.
+// Zero values keep DuckDB's default for the respective option.
+type Config struct {
+	// Threads is the number of threads used by the database.
+	Threads int
+	// MemoryLimit is the maximum memory of the database, e.g., "4GB".
+	MemoryLimit string
+	// AccessMode is the access mode of the database.
+	AccessMode AccessMode
+	// TempDirectory is the directory to write temporary files to.
+	TempDirectory string
+	// MaxTempDirectorySize is the maximum size of the temporary directory, e.g., "10GB".
+	MaxTempDirectorySize string
+	// DefaultOrder is the default order of ORDER BY clauses.
+	DefaultOrder Order
+	// AllowUnsignedExtensions allows loading unsigned extensions.
+	AllowUnsignedExtensions bool
+	// Options contains any additional configuration options by name.
+	// For example, "enable_external_access": "false".
+	Options map[string]string
+}
+
+// options validates the Config and returns all of its options by name.
+func (cfg Config) options() (map[string]string, error) {
+	options := make(map[string]string)
+
+	if cfg.Threads < 0 {
+		return nil, invalidInputError(strconv.Itoa(cfg.Threads), "a non-negative number of threads")
+	}
+	if cfg.Threads > 0 {
+		options["threads"] = strconv.Itoa(cfg.Threads)
+	}
+	if cfg.MemoryLimit != "" {
+		options["memory_limit"] = cfg.MemoryLimit
+	}
+
+	switch AccessMode(strings.ToLower(string(cfg.AccessMode))) {
+	case "":
+	case AccessModeAutomatic, AccessModeReadOnly, AccessModeReadWrite:
+		options["access_mode"] = string(cfg.AccessMode)
+	default:
+		return nil, invalidInputError(string(cfg.AccessMode), "automatic, read_only, or read_write")
+	}
+
+	if cfg.TempDirectory != "" {
+		options["temp_directory"] = cfg.TempDirectory
+	}
+	if cfg.MaxTempDirectorySize != "" {
+		options["max_temp_directory_size"] = cfg.MaxTempDirectorySize
+	}
+
+	switch Order(strings.ToLower(string(cfg.DefaultOrder))) {
+	case "":
+	case OrderAsc, OrderDesc:
+		options["default_order"] = string(cfg.DefaultOrder)
+	default:
+		return nil, invalidInputError(string(cfg.DefaultOrder), "asc or desc")
+	}
+
+	if cfg.AllowUnsignedExtensions {
+		options["allow_unsigned_extensions"] = "true"
+	}
+
+	for name, value := range cfg.Options {
+		key := strings.ToLower(name)
+		if _, ok := options[key]; ok {
+			return nil, duplicateNameError(name)
+		}
+		options[key] = value
+	}
+
+	return options, nil
+}
+
+// ConnectorOption configures a Connector.
+type ConnectorOption func(*connectorOptions)
+
+type connectorOptions struct {
+	// Callback to perform additional initialization steps.
+	connInitFn func(execer driver.ExecerContext) error
+	// True, if the database is opened without the instance cache.
+	bypassCache bool
+	// If not nil, the Connector waits for the lock on the database file.
+	lockWait *lockWait
+	// The hooks of the Connector.
+	hooks []Hooks
+	// If not nil, the Connector keeps a query history.
+	history *QueryHistoryOptions
+	// The session state the Connector resets.
+	sessionReset SessionResetOptions
+	// True, if the Connector enables savepoints.
+	savepoints bool
+	// True, if the Connector returns a result set per statement.
+	resultSets bool
+	// If not nil, the connections of the Connector cache prepared statements.
+	stmtCache *StmtCacheOptions
+}
+
+// WithConnInitFn sets a callback to perform additional initialization steps on each new connection.
+func WithConnInitFn(connInitFn func(execer driver.ExecerContext) error) ConnectorOption {
+	return func(opts *connectorOptions) {
+		opts.connInitFn = connInitFn
+	}
+}
+
+// WithoutInstanceCache opens the database without the instance cache.
+// The Connector does not share its database with other Connectors opening the same path,
+// and DuckDB releases the database file as soon as the Connector and its connections are closed.
+// DuckDB does not allow opening the same file more than once within a process,
+// so other Connectors must not open the same path at the same time.
+func WithoutInstanceCache() ConnectorOption {
+	return func(opts *connectorOptions) {
+		opts.bypassCache = true
+	}
+}
+
+// ConfigOption describes a global configuration option supported by DuckDB.
+type ConfigOption struct {
+	// Name is the name of the option.
+	Name string
+	// Description is DuckDB's description of the option.
+	Description string
+	// Default is the value of the option in a new in-memory database.
+	// It is empty for options of extensions that are not loaded by default.
+	Default string
+}
+
+// ConfigOptions returns all global configuration options supported by DuckDB, sorted by name.
+// These are the valid option names of DSNs and Config.Options.
+func ConfigOptions() ([]ConfigOption, error) {
+	defaults, err := configDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := configFlags()
+	options := make([]ConfigOption, 0, len(flags))
+	for _, name := range slices.Sorted(maps.Keys(flags)) {
+		options = append(options, ConfigOption{
+			Name:        name,
+			Description: flags[name],
+			Default:     defaults[name],
+		})
+	}
+
+	return options, nil
+}
+
+// configFlags returns the names and descriptions of all global configuration options supported by DuckDB.
+var configFlags = sync.OnceValue(
+	func() map[string]string {
+		count := mapping.ConfigCount()
+		flags := make(map[string]string, count)
+		for i := uint64(0); i < count; i++ {
+			var name, description string
+			if mapping.GetConfigFlag(i, &name, &description) == mapping.StateError {
+				continue
+			}
+			flags[name] = description
+		}
+		return flags
+	})
+
+// configDefaults returns the values of all settings of a new in-memory database.
+var configDefaults = sync.OnceValues(
+	func() (map[string]string, error) {
+		c, err := newConnector("", nil, connectorOptions{})
+		if err != nil {
+			return nil, err
+		}
+		db := sql.OpenDB(c)
+		defer db.Close()
+
+		r, err := db.Query(`SELECT name, value FROM duckdb_settings()`)
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		defaults := make(map[string]string)
+		for r.Next() {
+			var name string
+			var value sql.NullString
+			if err = r.Scan(&name, &value); err != nil {
+				return nil, err
+			}
+			defaults[name] = value.String
+		}
+
+		return defaults, r.Err()
+	})
+
+func validateConfigOption(name string) error {
+	if _, ok := configFlags()[strings.ToLower(name)]; !ok {
+		return unknownConfigOptionError(name, suggestConfigOptions(name))
+	}
+	return nil
+}
+
+// suggestConfigOptions returns up to three valid option names closest to name.
+func suggestConfigOptions(name string) []string {
+	const maxSuggestions = 3
+
+	name = strings.ToLower(name)
+	maxDistance := max(2, len(name)/3)
+
+	type candidate struct {
+		name     string
+		distance int
+	}
+	var candidates []candidate
+	for flag := range configFlags() {
+		distance := levenshtein(name, flag)
+		if distance <= maxDistance || strings.Contains(flag, name) {
+			candidates = append(candidates, candidate{flag, distance})
+		}
+	}
+
+	slices.SortFunc(candidates, func(a, b candidate) int {
+		if a.distance != b.distance {
+			return a.distance - b.distance
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, candidates[i].name)
+	}
+
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b.
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
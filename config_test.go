@@ -0,0 +1,89 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConnectorWithConfig(t *testing.T) {
+	t.Run("typed options", func(t *testing.T) {
+		c, err := NewConnectorWithConfig(``, Config{
+			Threads:                 2,
+			MemoryLimit:             "1GB",
+			AccessMode:              AccessModeReadWrite,
+			TempDirectory:           t.TempDir(),
+			MaxTempDirectorySize:    "2GB",
+			DefaultOrder:            OrderDesc,
+			AllowUnsignedExtensions: true,
+			Options: map[string]string{
+				"preserve_insertion_order": "false",
+			},
+		})
+		require.NoError(t, err)
+		defer closeConnectorWrapper(t, c)
+
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		checkIsMemory(t, db)
+
+		var (
+			threads        int64
+			accessMode     string
+			defaultOrder   string
+			insertionOrder bool
+		)
+		res := db.QueryRow(`SELECT current_setting('threads'), current_setting('access_mode'),
+			current_setting('default_order'), current_setting('preserve_insertion_order')`)
+		require.NoError(t, res.Scan(&threads, &accessMode, &defaultOrder, &insertionOrder))
+		require.Equal(t, int64(2), threads)
+		require.Equal(t, "read_write", accessMode)
+		require.Equal(t, "DESC", defaultOrder)
+		require.False(t, insertionOrder)
+	})
+
+	t.Run(":memory: with init function", func(t *testing.T) {
+		c, err := NewConnectorWithConfig(`:memory:`, Config{}, WithConnInitFn(func(execer driver.ExecerContext) error {
+			_, err := execer.ExecContext(context.Background(), `SET threads = 3`, nil)
+			return err
+		}))
+		require.NoError(t, err)
+		defer closeConnectorWrapper(t, c)
+
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		checkIsMemory(t, db)
+
+		var threads int64
+		require.NoError(t, db.QueryRow(`SELECT current_setting('threads')`).Scan(&threads))
+		require.Equal(t, int64(3), threads)
+	})
+}
+
+func TestConfigOptions(t *testing.T) {
+	options, err := ConfigOptions()
+	require.NoError(t, err)
+	require.NotEmpty(t, options)
+
+	byName := make(map[string]ConfigOption, len(options))
+	for _, option := range options {
+		byName[option.Name] = option
+	}
+
+	threads, ok := byName["threads"]
+	require.True(t, ok)
+	require.NotEmpty(t, threads.Description)
+	require.NotEmpty(t, threads.Default)
+
+	accessMode, ok := byName["access_mode"]
+	require.True(t, ok)
+	require.Equal(t, "automatic", accessMode.Default)
+
+	// Every option must be a valid DSN option.
+	for _, option := range options {
+		require.NoError(t, validateConfigOption(option.Name))
+	}
+}
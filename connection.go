@@ -18,20 +18,45 @@ type Conn struct {
 	conn mapping.Connection
 	// The internal DuckDB connection id.
 	id uint64
+	// The connector of this connection.
+	connector *Connector
 	// The context store of the connector of this connection.
 	ctxStore *contextStore
 	// True, if the connection has been closed, else false.
 	closed bool
 	// True, if the connection has an open transaction.
 	tx bool
+	// True, if the connection encountered a fatal error.
+	fatal bool
+	// True, if the connection executed a transaction statement outside of BeginTx.
+	txDirty bool
+	// True, if the connection executed a statement that might have changed its settings or variables.
+	settingsDirty bool
+	// True, if the connection executed a statement that might have created temporary objects.
+	tempDirty bool
+	// True, if the connection enabled the progress bar for WithProgress.
+	progress bool
+	// The session state after initializing the connection, if the connector restores it on reset.
+	baseline *sessionState
+	// The log of the current transaction, if the connector enables savepoints.
+	txLog *txLog
+	// The callbacks of the current transaction, if any.
+	txCallbacks *TxCallbacks
+	// The prepared statement cache, if the connector enables it.
+	stmtCache *stmtCache
 }
 
-func newConn(conn mapping.Connection, ctxStore *contextStore) *Conn {
-	return &Conn{
-		conn:     conn,
-		id:       extractConnId(conn),
-		ctxStore: ctxStore,
+func newConn(conn mapping.Connection, connector *Connector) *Conn {
+	c := &Conn{
+		conn:      conn,
+		id:        extractConnId(conn),
+		connector: connector,
+		ctxStore:  connector.ctxStore,
 	}
+	if connector.stmtCacheOpts != nil {
+		c.stmtCache = newStmtCache(connector.stmtCacheOpts.Size, connector.stmtCacheEpoch.Load())
+	}
+	return c
 }
 
 // CheckNamedValue implements the driver.NamedValueChecker interface.
@@ -50,10 +75,19 @@ func (conn *Conn) CheckNamedValue(nv *driver.NamedValue) error {
 	return driver.ErrSkip
 }
 
+// convertArg converts an argument like database/sql, for APIs bypassing database/sql.
+func (conn *Conn) convertArg(nv *driver.NamedValue) error {
+	err := conn.CheckNamedValue(nv)
+	if errors.Is(err, driver.ErrSkip) {
+		nv.Value, err = driver.DefaultParameterConverter.ConvertValue(nv.Value)
+	}
+	return err
+}
+
 // ExecContext executes a query that doesn't return rows, such as an INSERT or UPDATE.
 // It implements the driver.ExecerContext interface.
 func (conn *Conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
-	prepared, err := conn.prepareStmts(ctx, query)
+	prepared, cached, err := conn.prepareCached(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -62,7 +96,10 @@ func (conn *Conn) ExecContext(ctx context.Context, query string, args []driver.N
 	defer cleanupCtx()
 
 	res, err := prepared.ExecContext(ctx, args)
-	errClose := prepared.Close()
+	var errClose error
+	if !cached {
+		errClose = prepared.Close()
+	}
 	if err != nil {
 		if errClose != nil {
 			return nil, errors.Join(err, errClose)
@@ -79,16 +116,28 @@ func (conn *Conn) ExecContext(ctx context.Context, query string, args []driver.N
 // QueryContext executes a query that may return rows, such as a SELECT.
 // It implements the driver.QueryerContext interface.
 func (conn *Conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
-	prepared, err := conn.prepareStmts(ctx, query)
+	if conn.connector.resultSets {
+		return conn.queryResultSets(ctx, query, args)
+	}
+
+	prepared, cached, err := conn.prepareCached(ctx, query)
 	if err != nil {
 		return nil, err
 	}
+	return conn.queryPrepared(ctx, prepared, cached, args)
+}
 
+// queryPrepared executes a prepared statement, and returns its rows.
+// The rows close the statement after closing, unless it is cached.
+func (conn *Conn) queryPrepared(ctx context.Context, prepared *Stmt, cached bool, args []driver.NamedValue) (driver.Rows, error) {
 	cleanupCtx := conn.setContext(ctx)
 	defer cleanupCtx()
 
 	r, err := prepared.QueryContext(ctx, args)
 	if err != nil {
+		if cached {
+			return nil, err
+		}
 		errClose := prepared.Close()
 		if errClose != nil {
 			return nil, errors.Join(err, errClose)
@@ -96,7 +145,8 @@ func (conn *Conn) QueryContext(ctx context.Context, query string, args []driver.
 		return nil, err
 	}
 	// We must close the prepared statement after closing the rows r.
-	prepared.closeOnRowsClose = true
+	// The cache closes cached statements on eviction.
+	prepared.closeOnRowsClose = !cached
 
 	return r, nil
 }
@@ -117,6 +167,11 @@ func (conn *Conn) Prepare(query string) (driver.Stmt, error) {
 		return nil, errors.Join(errPrepare, errClosedCon)
 	}
 
+	query, err := conn.connector.beforePrepare(context.Background(), query)
+	if err != nil {
+		return nil, err
+	}
+
 	stmts, count, err := conn.extractStmts(query)
 	if err != nil {
 		return nil, err
@@ -126,7 +181,7 @@ func (conn *Conn) Prepare(query string) (driver.Stmt, error) {
 		return nil, errors.Join(errPrepare, errMissingPrepareContext)
 	}
 
-	return conn.prepareExtractedStmt(*stmts, 0)
+	return conn.prepareExtractedStmt(*stmts, 0, query)
 }
 
 // Begin is deprecated: Use BeginTx instead.
@@ -135,26 +190,33 @@ func (conn *Conn) Begin() (driver.Tx, error) {
 }
 
 // BeginTx starts and returns a new transaction.
+// If opts.ReadOnly is true, it starts a read-only transaction.
 // It implements the driver.ConnBeginTx interface.
 func (conn *Conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
 	if conn.tx {
 		return nil, errors.Join(errBeginTx, errMultipleTx)
 	}
 
-	if opts.ReadOnly {
-		return nil, errors.Join(errBeginTx, errReadOnlyTxNotSupported)
-	}
-
+	// DuckDB provides snapshot isolation, which satisfies the repeatable read
+	// and serializable isolation levels of database/sql.
 	switch sql.IsolationLevel(opts.Isolation) {
-	case sql.LevelDefault:
+	case sql.LevelDefault, sql.LevelSnapshot, sql.LevelRepeatableRead, sql.LevelSerializable:
 	default:
-		return nil, errors.Join(errBeginTx, errIsolationLevelNotSupported)
+		return nil, errors.Join(errBeginTx, isolationLevelError(sql.IsolationLevel(opts.Isolation)))
 	}
 
-	if _, err := conn.ExecContext(ctx, `BEGIN TRANSACTION`, nil); err != nil {
+	query := `BEGIN TRANSACTION`
+	if opts.ReadOnly {
+		query = `BEGIN TRANSACTION READ ONLY`
+	}
+	if _, err := conn.ExecContext(ctx, query, nil); err != nil {
 		return nil, err
 	}
 	conn.tx = true
+	conn.txCallbacks = txCallbacksFromContext(ctx)
+	if conn.connector.savepoints {
+		conn.txLog = &txLog{begin: query}
+	}
 
 	return &tx{conn}, nil
 }
@@ -166,6 +228,10 @@ func (conn *Conn) Close() error {
 		return errClosedCon
 	}
 	conn.closed = true
+	if conn.stmtCache != nil {
+		conn.stmtCache.clear()
+	}
+	conn.connector.removeConn(conn)
 	mapping.Disconnect(&conn.conn)
 	conn.ctxStore.delete(conn.id)
 
@@ -180,7 +246,9 @@ func (conn *Conn) extractStmts(query string) (*mapping.ExtractedStatements, mapp
 		errMsg := mapping.ExtractStatementsError(stmts)
 		mapping.DestroyExtracted(&stmts)
 		if errMsg != "" {
-			return nil, 0, getDuckDBError(errMsg)
+			err := getDuckDBError(errMsg)
+			conn.recordError(err)
+			return nil, 0, err
 		}
 		return nil, 0, errEmptyQuery
 	}
@@ -188,16 +256,17 @@ func (conn *Conn) extractStmts(query string) (*mapping.ExtractedStatements, mapp
 	return &stmts, count, nil
 }
 
-func (conn *Conn) prepareExtractedStmt(extractedStmts mapping.ExtractedStatements, i mapping.IdxT) (*Stmt, error) {
+func (conn *Conn) prepareExtractedStmt(extractedStmts mapping.ExtractedStatements, i mapping.IdxT, query string) (*Stmt, error) {
 	var stmt mapping.PreparedStatement
 	state := mapping.PrepareExtractedStatement(conn.conn, extractedStmts, i, &stmt)
 	if state == mapping.StateError {
 		err := getDuckDBError(mapping.PrepareError(stmt))
 		mapping.DestroyPrepare(&stmt)
+		conn.recordError(err)
 		return nil, err
 	}
 
-	return &Stmt{conn: conn, preparedStmt: &stmt}, nil
+	return &Stmt{conn: conn, preparedStmt: &stmt, query: query, index: i}, nil
 }
 
 func (conn *Conn) prepareStmts(ctx context.Context, query string) (*Stmt, error) {
@@ -208,6 +277,15 @@ func (conn *Conn) prepareStmts(ctx context.Context, query string) (*Stmt, error)
 	cleanupCtx := conn.setContext(ctx)
 	defer cleanupCtx()
 
+	query, err := conn.connector.beforePrepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return conn.prepareQuery(ctx, query)
+}
+
+// prepareQuery executes all but the last statement of a query, and returns the prepared last statement.
+func (conn *Conn) prepareQuery(ctx context.Context, query string) (*Stmt, error) {
 	stmts, count, errExtract := conn.extractStmts(query)
 	if errExtract != nil {
 		return nil, errExtract
@@ -215,7 +293,7 @@ func (conn *Conn) prepareStmts(ctx context.Context, query string) (*Stmt, error)
 	defer mapping.DestroyExtracted(stmts)
 
 	for i := mapping.IdxT(0); i < count-1; i++ {
-		preparedStmt, err := conn.prepareExtractedStmt(*stmts, i)
+		preparedStmt, err := conn.prepareExtractedStmt(*stmts, i, query)
 		if err != nil {
 			return nil, err
 		}
@@ -231,7 +309,7 @@ func (conn *Conn) prepareStmts(ctx context.Context, query string) (*Stmt, error)
 		}
 	}
 
-	return conn.prepareExtractedStmt(*stmts, count-1)
+	return conn.prepareExtractedStmt(*stmts, count-1, query)
 }
 
 // GetTableNames returns the tables names of a query.
@@ -302,19 +380,31 @@ func (conn *Conn) setContext(ctx context.Context) func() {
 	return conn.ctxStore.store(conn.id, ctx)
 }
 
-// contextStoreFromConn extracts the context store of a *sql.Conn connection.
-func contextStoreFromConn(c *sql.Conn) (*contextStore, error) {
-	var ctxStore *contextStore
+// connectorFromConn extracts the connector of a *sql.Conn connection.
+func connectorFromConn(c *sql.Conn) (*Connector, error) {
+	var connector *Connector
 
 	err := c.Raw(func(driverConn any) error {
 		conn := driverConn.(*Conn)
 		if conn.closed {
 			return errClosedCon
 		}
-		ctxStore = conn.ctxStore
+		connector = conn.connector
 
 		return nil
 	})
 
-	return ctxStore, err
+	return connector, err
+}
+
+// connFunc executes a function on an internal DuckDB connection.
+type connFunc func(fn func(conn mapping.Connection) error) error
+
+// rawConn returns a connFunc executing functions on the internal DuckDB connection of a *sql.Conn connection.
+func rawConn(c *sql.Conn) connFunc {
+	return func(fn func(conn mapping.Connection) error) error {
+		return c.Raw(func(driverConn any) error {
+			return fn(driverConn.(*Conn).conn)
+		})
+	}
 }
@@ -0,0 +1,88 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// QueryDescription describes the statement type, the result columns, and the parameters of a query.
+type QueryDescription struct {
+	// Type is the type of the statement.
+	Type StmtType
+	// ColumnNames contains the names of the result columns.
+	ColumnNames []string
+	// ColumnTypes contains the type information of the result columns.
+	ColumnTypes []TypeInfo
+	// ParamNames contains the names of the parameters.
+	ParamNames []string
+	// ParamTypes contains the type information of the parameters.
+	// Parameters with an unresolved type have the InternalType TYPE_INVALID.
+	ParamTypes []TypeInfo
+}
+
+// DescribeQuery prepares a query without executing it, and returns its description.
+// It expects a *sql.Conn connection, and a query containing a single statement,
+// as describing a statement can depend on executing the statements before it.
+func DescribeQuery(c *sql.Conn, query string) (QueryDescription, error) {
+	var desc QueryDescription
+	err := c.Raw(func(driverConn any) error {
+		conn := driverConn.(*Conn)
+		if conn.closed {
+			return errClosedCon
+		}
+
+		query, err := conn.connector.beforePrepare(context.Background(), query)
+		if err != nil {
+			return err
+		}
+
+		stmts, count, err := conn.extractStmts(query)
+		if err != nil {
+			return err
+		}
+		defer mapping.DestroyExtracted(stmts)
+		if count != 1 {
+			return getError(errAPI, errDescribeMultipleStmts)
+		}
+
+		s, err := conn.prepareExtractedStmt(*stmts, 0, query)
+		if err != nil {
+			return err
+		}
+		desc, err = s.describe()
+		return errors.Join(err, s.Close())
+	})
+	return desc, err
+}
+
+// describe returns the description of the prepared statement.
+func (s *Stmt) describe() (QueryDescription, error) {
+	var desc QueryDescription
+	var err error
+	if desc.Type, err = s.StatementType(); err != nil {
+		return desc, err
+	}
+	if desc.ColumnNames, err = s.ColumnNames(); err != nil {
+		return desc, err
+	}
+	if desc.ColumnTypes, err = s.ColumnTypeInfos(); err != nil {
+		return desc, err
+	}
+
+	for n := 1; n <= s.NumInput(); n++ {
+		name, err := s.ParamName(n)
+		if err != nil {
+			return desc, err
+		}
+		info, err := s.ParamTypeInfo(n)
+		if err != nil {
+			return desc, err
+		}
+		desc.ParamNames = append(desc.ParamNames, name)
+		desc.ParamTypes = append(desc.ParamTypes, info)
+	}
+	return desc, nil
+}
@@ -0,0 +1,143 @@
+package duckdb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtColumnTypeInfos(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	defer closeConnectorWrapper(t, c)
+
+	driverConn, err := c.Connect(context.Background())
+	require.NoError(t, err)
+	defer closeDriverConnWrapper(t, &driverConn)
+	conn := driverConn.(*Conn)
+
+	_, err = conn.ExecContext(context.Background(), `CREATE TYPE mood AS ENUM ('happy', 'sad')`, nil)
+	require.NoError(t, err)
+
+	s, err := conn.PrepareContext(context.Background(), `SELECT
+		1.5::DECIMAL(10, 2) AS d,
+		'happy'::mood AS m,
+		[[1, 2]] AS l,
+		{'a': 1, 'b': [true]} AS s,
+		MAP {'k': 1.0::DOUBLE} AS mp,
+		[1, 2, 3]::INTEGER[3] AS arr,
+		1::UNION(i INTEGER, v VARCHAR) AS u,
+		$id::BIGINT AS id`)
+	require.NoError(t, err)
+	stmt := s.(*Stmt)
+
+	names, err := stmt.ColumnNames()
+	require.NoError(t, err)
+	require.Equal(t, []string{"d", "m", "l", "s", "mp", "arr", "u", "id"}, names)
+
+	infos, err := stmt.ColumnTypeInfos()
+	require.NoError(t, err)
+	require.Len(t, infos, 8)
+
+	require.Equal(t, TYPE_DECIMAL, infos[0].InternalType())
+	require.Equal(t, &DecimalDetails{Width: 10, Scale: 2}, infos[0].Details())
+
+	require.Equal(t, TYPE_ENUM, infos[1].InternalType())
+	require.Equal(t, &EnumDetails{Values: []string{"happy", "sad"}}, infos[1].Details())
+
+	require.Equal(t, TYPE_LIST, infos[2].InternalType())
+	inner := infos[2].Details().(*ListDetails).Child
+	require.Equal(t, TYPE_LIST, inner.InternalType())
+	require.Equal(t, TYPE_INTEGER, inner.Details().(*ListDetails).Child.InternalType())
+	require.Nil(t, inner.Details().(*ListDetails).Child.Details())
+
+	require.Equal(t, TYPE_STRUCT, infos[3].InternalType())
+	entries := infos[3].Details().(*StructDetails).Entries
+	require.Len(t, entries, 2)
+	require.Equal(t, "a", entries[0].Name())
+	require.Equal(t, TYPE_INTEGER, entries[0].Info().InternalType())
+	require.Equal(t, "b", entries[1].Name())
+	require.Equal(t, TYPE_BOOLEAN, entries[1].Info().Details().(*ListDetails).Child.InternalType())
+
+	require.Equal(t, TYPE_MAP, infos[4].InternalType())
+	mapDetails := infos[4].Details().(*MapDetails)
+	require.Equal(t, TYPE_VARCHAR, mapDetails.Key.InternalType())
+	require.Equal(t, TYPE_DOUBLE, mapDetails.Value.InternalType())
+
+	require.Equal(t, TYPE_ARRAY, infos[5].InternalType())
+	arrayDetails := infos[5].Details().(*ArrayDetails)
+	require.Equal(t, TYPE_INTEGER, arrayDetails.Child.InternalType())
+	require.Equal(t, uint64(3), arrayDetails.Size)
+
+	require.Equal(t, TYPE_UNION, infos[6].InternalType())
+	unionDetails := infos[6].Details().(*UnionDetails)
+	require.Equal(t, []string{"i", "v"}, unionDetails.Names)
+	require.Equal(t, TYPE_INTEGER, unionDetails.Types[0].InternalType())
+	require.Equal(t, TYPE_VARCHAR, unionDetails.Types[1].InternalType())
+
+	require.Equal(t, TYPE_BIGINT, infos[7].InternalType())
+
+	// The described types are valid TypeInfo inputs.
+	_, err = NewListInfo(infos[3])
+	require.NoError(t, err)
+
+	// The statement did not execute.
+	require.Zero(t, c.Stats().Statements[STATEMENT_TYPE_SELECT])
+
+	info, err := stmt.ParamTypeInfo(1)
+	require.NoError(t, err)
+	require.Equal(t, TYPE_BIGINT, info.InternalType())
+
+	_, err = stmt.ParamTypeInfo(2)
+	require.ErrorContains(t, err, paramIndexErrMsg)
+
+	require.NoError(t, stmt.Close())
+	_, err = stmt.ColumnNames()
+	require.ErrorIs(t, err, errClosedStmt)
+	_, err = stmt.ColumnTypeInfos()
+	require.ErrorIs(t, err, errClosedStmt)
+	_, err = stmt.ParamTypeInfo(1)
+	require.ErrorIs(t, err, errClosedStmt)
+}
+
+func TestDescribeQuery(t *testing.T) {
+	db := openDbWrapper(t, ``)
+	defer closeDbWrapper(t, db)
+
+	createTable(t, db, `CREATE TABLE foo(bar VARCHAR, baz DECIMAL(18, 3)[])`)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	desc, err := DescribeQuery(conn, `SELECT bar, baz FROM foo WHERE bar = $name AND len(baz) > $min_len`)
+	require.NoError(t, err)
+	require.Equal(t, STATEMENT_TYPE_SELECT, desc.Type)
+	require.Equal(t, []string{"bar", "baz"}, desc.ColumnNames)
+	require.Equal(t, TYPE_VARCHAR, desc.ColumnTypes[0].InternalType())
+	child := desc.ColumnTypes[1].Details().(*ListDetails).Child
+	require.Equal(t, &DecimalDetails{Width: 18, Scale: 3}, child.Details())
+	require.Len(t, desc.ParamTypes, 2)
+	require.Equal(t, []string{"name", "min_len"}, desc.ParamNames)
+	require.Equal(t, TYPE_VARCHAR, desc.ParamTypes[0].InternalType())
+	require.Equal(t, TYPE_BIGINT, desc.ParamTypes[1].InternalType())
+
+	// Unresolved parameter types.
+	desc, err = DescribeQuery(conn, `SELECT * FROM (VALUES (?)) t(a)`)
+	require.NoError(t, err)
+	require.Equal(t, TYPE_INVALID, desc.ParamTypes[0].InternalType())
+
+	// Describing a statement does not execute it.
+	desc, err = DescribeQuery(conn, `INSERT INTO foo VALUES ('a', [1.5])`)
+	require.NoError(t, err)
+	require.Equal(t, STATEMENT_TYPE_INSERT, desc.Type)
+
+	var count int
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT count(*) FROM foo`).Scan(&count))
+	require.Equal(t, 0, count)
+
+	_, err = DescribeQuery(conn, `CREATE TABLE bar(i INTEGER); SELECT * FROM bar`)
+	testError(t, err, errAPI.Error(), errDescribeMultipleStmts.Error())
+
+	_, err = DescribeQuery(conn, `SELECT * FROM does_not_exist`)
+	require.ErrorContains(t, err, "does_not_exist")
+}
@@ -8,19 +8,18 @@ import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
+	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/marcboeker/go-duckdb/mapping"
 )
 
-var GetInstanceCache = sync.OnceValue(
-	func() mapping.InstanceCache {
-		return mapping.CreateInstanceCache()
-	})
-
 func init() {
 	sql.Register("duckdb", Driver{})
 }
@@ -47,18 +46,58 @@ type Connector struct {
 	db mapping.Database
 	// Callback to perform additional initialization steps.
 	connInitFn func(execer driver.ExecerContext) error
+	// The hooks observing and intercepting statements.
+	hooks []Hooks
+	// The statistics of the connector.
+	stats *connectorStats
+	// The query history of the connector, if any.
+	history *queryHistory
+	// The session state the connector resets before database/sql reuses a connection.
+	sessionReset SessionResetOptions
+	// True, if the connections of the connector record their transactions to emulate savepoints.
+	savepoints bool
+	// True, if queries with multiple statements return a result set per statement.
+	resultSets bool
+	// The options of the prepared statement caches of the connections, if the connector enables them.
+	stmtCacheOpts *StmtCacheOptions
+	// stmtCacheEpoch is incremented to invalidate the prepared statement caches.
+	stmtCacheEpoch atomic.Uint64
 	// ctxStore stores the context of the current query/exec/etc. of a connection.
 	ctxStore *contextStore
+	// The path of the database. It is empty for unnamed in-memory databases.
+	path string
+	// The path of the database in the instance cache, if any.
+	cachePath string
+	// The access mode the database was opened with.
+	accessMode AccessMode
+
+	// mu protects the following fields, e.g., against concurrent closing through EvictCachedDatabase.
+	mu sync.Mutex
+	// The open connections of the connector.
+	conns map[*Conn]struct{}
+	// The number of currently executing statements.
+	activeStmts int
+	// True, if the connector is shutting down.
+	// It then no longer creates new connections, and no longer executes new statements.
+	shutdown bool
+	// drained is closed when the last executing statement finishes during shutdown.
+	drained chan struct{}
 	// True, if the connector has been closed, else false.
 	closed bool
 }
 
+// inMemoryName is the DSN and path prefix of in-memory databases.
+const inMemoryName = ":memory:"
+
 // NewConnector opens a new Connector for a DuckDB database.
+// An empty DSN or ":memory:" opens a new in-memory database.
+// A named in-memory DSN, e.g., ":memory:name", opens a process-wide in-memory database.
+// All Connectors with the same name share that database until the last one of them closes.
 // The user must close the Connector, if it is not passed to the sql.OpenDB function.
 // Otherwise, sql.DB closes the Connector when calling sql.DB.Close().
 func NewConnector(dsn string, connInitFn func(execer driver.ExecerContext) error) (*Connector, error) {
 	inMemory := false
-	const inMemoryName = ":memory:"
+	path := ""
 
 	// If necessary, trim the in-memory prefix, and determine if this is an in-memory database.
 	if dsn == inMemoryName || strings.HasPrefix(dsn, inMemoryName+"?") {
@@ -66,6 +105,12 @@ func NewConnector(dsn string, connInitFn func(execer driver.ExecerContext) error
 		inMemory = true
 	} else if dsn == "" || strings.HasPrefix(dsn, "?") {
 		inMemory = true
+	} else if strings.HasPrefix(dsn, inMemoryName) {
+		// A named in-memory database, e.g., ":memory:name?threads=4".
+		// We keep the name as its path, so that it resolves to a shared instance.
+		path = getDBPath(dsn)
+		dsn = dsn[len(path):]
+		inMemory = true
 	}
 
 	parsedDSN, err := url.Parse(dsn)
@@ -73,33 +118,117 @@ func NewConnector(dsn string, connInitFn func(execer driver.ExecerContext) error
 		return nil, getError(errParseDSN, err)
 	}
 
-	config, err := prepareConfig(parsedDSN)
+	options := make(map[string]string)
+	for k, v := range parsedDSN.Query() {
+		if len(v) == 0 {
+			continue
+		}
+		options[k] = v[0]
+	}
+
+	if !inMemory {
+		path = getDBPath(dsn)
+	}
+
+	return newConnector(path, options, connectorOptions{connInitFn: connInitFn})
+}
+
+// NewConnectorWithConfig opens a new Connector for the DuckDB database at path.
+// An empty path or ":memory:" opens a new in-memory database.
+// A named in-memory path, e.g., ":memory:name", opens a process-wide in-memory database.
+// Unlike NewConnector, it validates the configuration before opening the database.
+// The user must close the Connector, if it is not passed to the sql.OpenDB function.
+// Otherwise, sql.DB closes the Connector when calling sql.DB.Close().
+func NewConnectorWithConfig(path string, config Config, opts ...ConnectorOption) (*Connector, error) {
+	options, err := config.options()
+	if err != nil {
+		return nil, getError(errInvalidConfig, err)
+	}
+
+	var connectorOpts connectorOptions
+	for _, opt := range opts {
+		opt(&connectorOpts)
+	}
+
+	if path == inMemoryName {
+		path = ""
+	}
+
+	return newConnector(path, options, connectorOpts)
+}
+
+// newConnector opens the database at path with the configuration options.
+// An empty path opens a new in-memory database.
+// Other paths, including named in-memory databases, resolve through the instance cache.
+func newConnector(path string, options map[string]string, opts connectorOptions) (*Connector, error) {
+	c := &Connector{
+		connInitFn:    opts.connInitFn,
+		hooks:         opts.hooks,
+		sessionReset:  opts.sessionReset,
+		savepoints:    opts.savepoints,
+		resultSets:    opts.resultSets,
+		stmtCacheOpts: opts.stmtCache,
+		stats:         newConnectorStats(),
+		ctxStore:      newContextStore(),
+		path:          path,
+		accessMode:    AccessModeReadWrite,
+		conns:         make(map[*Conn]struct{}),
+	}
+	if strings.EqualFold(options["access_mode"], string(AccessModeReadOnly)) {
+		c.accessMode = AccessModeReadOnly
+	}
+	if opts.history != nil {
+		c.history = newQueryHistory(*opts.history, c)
+		c.hooks = append(slices.Clone(c.hooks), c.history)
+	}
+
+	var err error
+	if opts.lockWait != nil && path != "" {
+		err = c.openWithLockWait(path, options, opts)
+	} else {
+		err = c.open(path, options, opts)
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	return c, nil
+}
+
+// open opens the database at path with the configuration options.
+func (c *Connector) open(path string, options map[string]string, opts connectorOptions) error {
+	config, err := prepareConfig(options)
+	if err != nil {
+		return err
+	}
 	defer mapping.DestroyConfig(&config)
 
-	var db mapping.Database
 	var errMsg string
 	var state mapping.State
 
-	if inMemory {
+	if path == "" {
 		// Open an in-memory database.
-		state = mapping.OpenExt("", &db, config, &errMsg)
+		state = mapping.OpenExt("", &c.db, config, &errMsg)
+	} else if opts.bypassCache {
+		// Open a file-backed database without sharing it.
+		state = mapping.OpenExt(path, &c.db, config, &errMsg)
 	} else {
-		// Open a file-backed database.
-		state = mapping.GetOrCreateFromCache(GetInstanceCache(), getDBPath(dsn), &db, config, &errMsg)
+		// Open a file-backed or named in-memory database.
+		state = instances.open(c, path, config, &errMsg)
 	}
 	if state == mapping.StateError {
-		mapping.Close(&db)
-		return nil, getError(errConnect, getDuckDBError(errMsg))
+		mapping.Close(&c.db)
+		return getError(errConnect, getDuckDBError(errMsg))
 	}
 
-	return &Connector{
-		db:         db,
-		connInitFn: connInitFn,
-		ctxStore:   newContextStore(),
-	}, nil
+	return nil
+}
+
+// AccessMode returns the access mode of the Connector's database.
+// It is AccessModeReadOnly, if the database was opened read-only, e.g., after falling back
+// to read-only mode while waiting for a lock. Otherwise, it is AccessModeReadWrite.
+func (c *Connector) AccessMode() AccessMode {
+	return c.accessMode
 }
 
 func (*Connector) Driver() driver.Driver {
@@ -107,19 +236,44 @@ func (*Connector) Driver() driver.Driver {
 }
 
 func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, getError(errConnect, errClosedCon)
+	}
+	if c.shutdown {
+		c.mu.Unlock()
+		return nil, getError(errConnect, errShutdown)
+	}
 	var mc mapping.Connection
-	if mapping.Connect(c.db, &mc) == mapping.StateError {
+	state := mapping.Connect(c.db, &mc)
+	if state == mapping.StateError {
+		c.mu.Unlock()
 		return nil, getError(errConnect, nil)
 	}
-
-	conn := newConn(mc, c.ctxStore)
+	conn := newConn(mc, c)
+	c.conns[conn] = struct{}{}
+	c.mu.Unlock()
 
 	cleanupCtx := c.ctxStore.store(conn.id, ctx)
 	defer cleanupCtx()
 
+	if c.history != nil {
+		if err := c.history.enableProfiling(conn); err != nil {
+			return nil, errors.Join(getError(errConnect, err), conn.Close())
+		}
+	}
 	if c.connInitFn != nil {
 		if err := c.connInitFn(conn); err != nil {
-			return nil, err
+			return nil, errors.Join(err, conn.Close())
+		}
+	}
+	if err := conn.captureSession(); err != nil {
+		return nil, errors.Join(getError(errConnect, err), conn.Close())
+	}
+	if conn.stmtCache != nil {
+		if err := conn.prewarmStmtCache(); err != nil {
+			return nil, errors.Join(getError(errConnect, err), conn.Close())
 		}
 	}
 
@@ -127,15 +281,163 @@ func (c *Connector) Connect(ctx context.Context) (driver.Conn, error) {
 }
 
 func (c *Connector) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.closed {
 		return nil
 	}
+	if c.cachePath != "" {
+		instances.release(c)
+	}
 	mapping.Close(&c.db)
 	c.closed = true
 
 	return nil
 }
 
+// Shutdown gracefully shuts down the Connector.
+// First, it stops creating new connections and executing new statements,
+// and waits for all executing statements to finish.
+// Then, it checkpoints file-backed databases and closes the Connector.
+// If ctx is done before all statements finish, it interrupts them, checkpoints, and closes the Connector
+// without waiting for the interrupted statements, and returns the context's error.
+// The checkpoint fails, if the interrupted statements did not finish yet.
+// Existing connections remain valid until they are closed, but they can only end their open transactions.
+func (c *Connector) Shutdown(ctx context.Context) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.shutdown = true
+	drained := make(chan struct{})
+	if c.activeStmts == 0 {
+		close(drained)
+	} else {
+		c.drained = drained
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		c.interrupt()
+		return errors.Join(ctx.Err(), c.checkpoint(), c.Close())
+	}
+
+	errCheckpoint := c.checkpoint()
+	return errors.Join(errCheckpoint, c.Close())
+}
+
+// interrupt interrupts the statements of all connections.
+func (c *Connector) interrupt() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for conn := range c.conns {
+		mapping.Interrupt(conn.conn)
+	}
+}
+
+// withConn executes fn on a new internal connection to the database of the connector.
+func (c *Connector) withConn(fn func(conn mapping.Connection) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return getError(errConnect, errClosedCon)
+	}
+
+	var mc mapping.Connection
+	if mapping.Connect(c.db, &mc) == mapping.StateError {
+		return getError(errConnect, nil)
+	}
+	defer mapping.Disconnect(&mc)
+
+	return fn(mc)
+}
+
+// checkpoint checkpoints a file-backed database.
+func (c *Connector) checkpoint() error {
+	if c.path == "" || strings.HasPrefix(c.path, inMemoryName) || c.accessMode == AccessModeReadOnly {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return nil
+	}
+
+	var mc mapping.Connection
+	if mapping.Connect(c.db, &mc) == mapping.StateError {
+		return getError(errConnect, nil)
+	}
+	defer mapping.Disconnect(&mc)
+
+	var res mapping.Result
+	defer mapping.DestroyResult(&res)
+	if mapping.Query(mc, `CHECKPOINT`, &res) == mapping.StateError {
+		return getDuckDBError(mapping.ResultError(&res))
+	}
+
+	return nil
+}
+
+// beginStmt registers an executing statement.
+// During shutdown, it only accepts statements ending an open transaction, if endsTx is true.
+func (c *Connector) beginStmt(endsTx bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.shutdown && !endsTx {
+		return errShutdown
+	}
+	c.activeStmts++
+
+	return nil
+}
+
+// resumeStmt registers a statement that resumes its execution, e.g., a PendingQuery executing its next task.
+// Unlike beginStmt, it does not reject statements during shutdown, as the statement began before.
+func (c *Connector) resumeStmt() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.activeStmts++
+}
+
+// endStmt unregisters an executing statement.
+func (c *Connector) endStmt() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.activeStmts--
+	if c.activeStmts == 0 && c.drained != nil {
+		close(c.drained)
+		c.drained = nil
+	}
+}
+
+// connById returns the open connection with the id, or nil.
+func (c *Connector) connById(id uint64) *Conn {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for conn := range c.conns {
+		if conn.id == id {
+			return conn
+		}
+	}
+	return nil
+}
+
+// removeConn stops tracking a closed connection.
+func (c *Connector) removeConn(conn *Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+}
+
 func getDBPath(dsn string) string {
 	idx := strings.Index(dsn, "?")
 	if idx < 0 {
@@ -144,7 +446,7 @@ func getDBPath(dsn string) string {
 	return dsn[0:idx]
 }
 
-func prepareConfig(parsedDSN *url.URL) (mapping.Config, error) {
+func prepareConfig(options map[string]string) (mapping.Config, error) {
 	var config mapping.Config
 	if mapping.CreateConfig(&config) == mapping.StateError {
 		mapping.DestroyConfig(&config)
@@ -155,16 +457,16 @@ func prepareConfig(parsedDSN *url.URL) (mapping.Config, error) {
 		return config, err
 	}
 
-	// Early-out, if the DSN does not contain configuration options.
-	if len(parsedDSN.RawQuery) == 0 {
-		return config, nil
+	// Reject unknown options before setting any of them.
+	for k := range options {
+		if err := validateConfigOption(k); err != nil {
+			mapping.DestroyConfig(&config)
+			return config, getError(errSetConfig, err)
+		}
 	}
 
-	for k, v := range parsedDSN.Query() {
-		if len(v) == 0 {
-			continue
-		}
-		if err := setConfigOption(config, k, v[0]); err != nil {
+	for _, k := range slices.Sorted(maps.Keys(options)) {
+		if err := setConfigOption(config, k, options[k]); err != nil {
 			return config, err
 		}
 	}
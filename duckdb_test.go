@@ -163,6 +163,79 @@ func TestOpen(t *testing.T) {
 	})
 }
 
+func TestNamedInMemory(t *testing.T) {
+	t.Run("shared catalog", func(t *testing.T) {
+		c1 := newConnectorWrapper(t, `:memory:shared?threads=2`, nil)
+		defer closeConnectorWrapper(t, c1)
+		db1 := sql.OpenDB(c1)
+		defer closeDbWrapper(t, db1)
+		checkIsMemory(t, db1)
+
+		c2 := newConnectorWrapper(t, `:memory:shared?threads=2`, func(execer driver.ExecerContext) error {
+			_, err := execer.ExecContext(context.Background(), `SET search_path = 'main'`, nil)
+			return err
+		})
+		defer closeConnectorWrapper(t, c2)
+		db2 := sql.OpenDB(c2)
+		defer closeDbWrapper(t, db2)
+
+		createTable(t, db1, `CREATE TABLE shared AS SELECT 42 AS i`)
+
+		var i int
+		require.NoError(t, db2.QueryRow(`SELECT i FROM shared`).Scan(&i))
+		require.Equal(t, 42, i)
+	})
+
+	t.Run("different names", func(t *testing.T) {
+		db1 := openDbWrapper(t, `:memory:first`)
+		defer closeDbWrapper(t, db1)
+		db2 := openDbWrapper(t, `:memory:second`)
+		defer closeDbWrapper(t, db2)
+
+		createTable(t, db1, `CREATE TABLE tbl (i INTEGER)`)
+		_, err := db2.Exec(`SELECT * FROM tbl`)
+		require.ErrorContains(t, err, "Catalog Error")
+	})
+
+	t.Run("unnamed", func(t *testing.T) {
+		db1 := openDbWrapper(t, `:memory:`)
+		defer closeDbWrapper(t, db1)
+		db2 := openDbWrapper(t, `:memory:`)
+		defer closeDbWrapper(t, db2)
+
+		createTable(t, db1, `CREATE TABLE tbl (i INTEGER)`)
+		_, err := db2.Exec(`SELECT * FROM tbl`)
+		require.ErrorContains(t, err, "Catalog Error")
+	})
+
+	t.Run("NewConnectorWithConfig", func(t *testing.T) {
+		c1, err := NewConnectorWithConfig(`:memory:config`, Config{Threads: 1})
+		require.NoError(t, err)
+		defer closeConnectorWrapper(t, c1)
+		db1 := sql.OpenDB(c1)
+		defer closeDbWrapper(t, db1)
+
+		db2 := openDbWrapper(t, `:memory:config?threads=1`)
+		defer closeDbWrapper(t, db2)
+
+		createTable(t, db1, `CREATE TABLE tbl AS SELECT 1 AS i`)
+		var i int
+		require.NoError(t, db2.QueryRow(`SELECT i FROM tbl`).Scan(&i))
+		require.Equal(t, 1, i)
+	})
+
+	t.Run("released after close", func(t *testing.T) {
+		db := openDbWrapper(t, `:memory:released`)
+		createTable(t, db, `CREATE TABLE tbl (i INTEGER)`)
+		closeDbWrapper(t, db)
+
+		db = openDbWrapper(t, `:memory:released`)
+		defer closeDbWrapper(t, db)
+		_, err := db.Exec(`SELECT * FROM tbl`)
+		require.ErrorContains(t, err, "Catalog Error")
+	})
+}
+
 func TestConnectorBootQueries(t *testing.T) {
 	t.Run("README connector example", func(t *testing.T) {
 		db := openDbWrapper(t, `foo.db`)
@@ -1,6 +1,7 @@
 package duckdb
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"strings"
@@ -66,21 +67,59 @@ func duplicateNameError(name string) error {
 	return fmt.Errorf("%s: %s", duplicateNameErrMsg, name)
 }
 
+func notCachedError(path string) error {
+	return fmt.Errorf("%s: %s", notCachedErrMsg, path)
+}
+
+func unknownConfigOptionError(name string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return fmt.Errorf("%s: %s", unknownConfigOptionErrMsg, name)
+	}
+	return fmt.Errorf("%s: %s, %s: %s", unknownConfigOptionErrMsg, name, suggestionsErrMsg, strings.Join(suggestions, ", "))
+}
+
+func unknownSavepointError(name string) error {
+	return fmt.Errorf("%s: %s", unknownSavepointErrMsg, name)
+}
+
+func notReplayableError(reason string) error {
+	return fmt.Errorf("%s: %s", notReplayableErrMsg, reason)
+}
+
+func replayRowsChangedError(logged, replayed int64) error {
+	return fmt.Errorf("%s: %d instead of %d", replayRowsChangedErrMsg, replayed, logged)
+}
+
+func missingParamError(name string) error {
+	return fmt.Errorf("%s: %s", missingParamErrMsg, name)
+}
+
+func isolationLevelError(level sql.IsolationLevel) error {
+	return fmt.Errorf("%w: got %s", errIsolationLevelNotSupported, level)
+}
+
 const (
-	driverErrMsg           = "database/sql/driver"
-	castErrMsg             = "cast error"
-	convertErrMsg          = "conversion error"
-	invalidInputErrMsg     = "invalid input"
-	structFieldErrMsg      = "invalid STRUCT field"
-	columnCountErrMsg      = "invalid column count"
-	unsupportedTypeErrMsg  = "unsupported data type"
-	invalidatedAppenderMsg = "appended and not yet flushed data has been invalidated due to error"
-	tryOtherFuncErrMsg     = "please try this function instead"
-	indexErrMsg            = "index"
-	unknownTypeErrMsg      = "unknown type"
-	interfaceIsNilErrMsg   = "interface is nil"
-	duplicateNameErrMsg    = "duplicate name"
-	paramIndexErrMsg       = "invalid parameter index"
+	driverErrMsg              = "database/sql/driver"
+	castErrMsg                = "cast error"
+	convertErrMsg             = "conversion error"
+	invalidInputErrMsg        = "invalid input"
+	structFieldErrMsg         = "invalid STRUCT field"
+	columnCountErrMsg         = "invalid column count"
+	unsupportedTypeErrMsg     = "unsupported data type"
+	invalidatedAppenderMsg    = "appended and not yet flushed data has been invalidated due to error"
+	tryOtherFuncErrMsg        = "please try this function instead"
+	indexErrMsg               = "index"
+	unknownTypeErrMsg         = "unknown type"
+	interfaceIsNilErrMsg      = "interface is nil"
+	duplicateNameErrMsg       = "duplicate name"
+	paramIndexErrMsg          = "invalid parameter index"
+	unknownConfigOptionErrMsg = "unknown config option"
+	unknownSavepointErrMsg    = "unknown savepoint"
+	missingParamErrMsg        = "missing parameter"
+	notReplayableErrMsg       = "a statement before the savepoint cannot be replayed"
+	replayRowsChangedErrMsg   = "a replayed statement changed a different number of rows"
+	suggestionsErrMsg         = "did you mean"
+	notCachedErrMsg           = "database is not in the instance cache"
 )
 
 var (
@@ -88,13 +127,16 @@ var (
 	errAPI        = errors.New("API error")
 	errVectorSize = errors.New("data chunks cannot exceed duckdb's internal vector size")
 
-	errConnect      = errors.New("could not connect to database")
-	errParseDSN     = errors.New("could not parse DSN for database")
-	errSetConfig    = errors.New("could not set invalid or local option for global database config")
-	errCreateConfig = errors.New("could not create config for database")
+	errConnect       = errors.New("could not connect to database")
+	errParseDSN      = errors.New("could not parse DSN for database")
+	errSetConfig     = errors.New("could not set invalid or local option for global database config")
+	errCreateConfig  = errors.New("could not create config for database")
+	errInvalidConfig = errors.New("invalid config for database")
 
-	errInvalidCon = errors.New("not a DuckDB driver connection")
-	errClosedCon  = errors.New("closed connection")
+	errShutdown     = errors.New("connector is shutting down")
+	errInvalidCon   = errors.New("not a DuckDB driver connection")
+	errClosedCon    = errors.New("closed connection")
+	errConnNotFound = errors.New("no open connection with this id")
 
 	errClosedStmt        = errors.New("closed statement")
 	errUninitializedStmt = errors.New("uninitialized statement")
@@ -107,8 +149,22 @@ var (
 	errNotBound                   = errors.New("parameters have not been bound")
 	errBeginTx                    = errors.New("could not begin transaction")
 	errMultipleTx                 = errors.New("multiple transactions")
-	errReadOnlyTxNotSupported     = errors.New("read-only transactions are not supported")
-	errIsolationLevelNotSupported = errors.New("isolation level not supported: go-duckdb only supports the default isolation level")
+	errIsolationLevelNotSupported = errors.New("isolation level not supported: go-duckdb supports the default, snapshot, repeatable read, and serializable isolation levels")
+	errSavepoint                  = errors.New("could not use savepoint")
+	errSavepointsDisabled         = errors.New("savepoints are disabled: use WithSavepoints")
+	errNoTx                       = errors.New("no active transaction")
+	errTxCallback                 = errors.New("could not register transaction callback")
+
+	errInstanceCacheInUse = errors.New("the instance cache has open Connectors")
+
+	errWriteQueueClosed      = errors.New("write queue is closed")
+	errBatch                 = errors.New("could not execute batch")
+	errBatchColumnNotSlice   = errors.New("batch column is not a slice")
+	errBatchColumnLength     = errors.New("batch columns have different lengths")
+	errPendingQueryConsumed  = errors.New("the result of the pending query has already been consumed")
+	errDescribeMultipleStmts = errors.New("cannot describe a query with multiple statements")
+	errSavepointAppended     = errors.New("the transaction appended rows, which cannot be rolled back to a savepoint")
+	errSavepointReplay       = errors.New("could not replay the transaction: the transaction is aborted")
 
 	errAppenderCreation         = errors.New("could not create appender")
 	errAppenderClose            = errors.New("could not close appender")
@@ -142,6 +198,7 @@ var (
 	errTableUDFMissingBindArgs = fmt.Errorf("%w: missing bind arguments", errTableUDFCreate)
 	errTableUDFArgumentIsNil   = fmt.Errorf("%w: argument is nil", errTableUDFCreate)
 	errTableUDFColumnTypeIsNil = fmt.Errorf("%w: column type is nil", errTableUDFCreate)
+	errTableUDFInvalidType     = fmt.Errorf("%w: unsupported table function type", errTableUDFCreate)
 
 	errProfilingInfoEmpty = errors.New("no profiling information available for this connection")
 )
@@ -153,7 +210,7 @@ const (
 	ErrorTypeOutOfRange           = ErrorType(mapping.ErrorTypeOutOfRange)           // The type's value is out of range.
 	ErrorTypeConversion           = ErrorType(mapping.ErrorTypeConversion)           // Conversion/casting error.
 	ErrorTypeUnknownType          = ErrorType(mapping.ErrorTypeUnknownType)          // The type is unknown.
-	ErrorTypeDecimal              = ErrorType(mapping.TypeDecimal)                   // Decimal-related error.
+	ErrorTypeDecimal              = ErrorType(mapping.ErrorTypeDecimal)              // Decimal-related error.
 	ErrorTypeMismatchType         = ErrorType(mapping.ErrorTypeMismatchType)         // Types don't match.
 	ErrorTypeDivideByZero         = ErrorType(mapping.ErrorTypeDivideByZero)         // Division by zero.
 	ErrorTypeObjectSize           = ErrorType(mapping.ErrorTypeObjectSize)           // Exceeds object size.
@@ -194,6 +251,60 @@ const (
 	ErrorTypeInvalidConfiguration = ErrorType(mapping.ErrorTypeInvalidConfiguration) // Indicates an invalid configuration, e.g., a missing Secret parameter, or a mandatory setting is not provided.
 )
 
+var errorTypeNames = map[ErrorType]string{
+	ErrorTypeInvalid:              "Invalid",
+	ErrorTypeOutOfRange:           "OutOfRange",
+	ErrorTypeConversion:           "Conversion",
+	ErrorTypeUnknownType:          "UnknownType",
+	ErrorTypeDecimal:              "Decimal",
+	ErrorTypeMismatchType:         "MismatchType",
+	ErrorTypeDivideByZero:         "DivideByZero",
+	ErrorTypeObjectSize:           "ObjectSize",
+	ErrorTypeInvalidType:          "InvalidType",
+	ErrorTypeSerialization:        "Serialization",
+	ErrorTypeTransaction:          "Transaction",
+	ErrorTypeNotImplemented:       "NotImplemented",
+	ErrorTypeExpression:           "Expression",
+	ErrorTypeCatalog:              "Catalog",
+	ErrorTypeParser:               "Parser",
+	ErrorTypePlanner:              "Planner",
+	ErrorTypeScheduler:            "Scheduler",
+	ErrorTypeExecutor:             "Executor",
+	ErrorTypeConstraint:           "Constraint",
+	ErrorTypeIndex:                "Index",
+	ErrorTypeStat:                 "Stat",
+	ErrorTypeConnection:           "Connection",
+	ErrorTypeSyntax:               "Syntax",
+	ErrorTypeSettings:             "Settings",
+	ErrorTypeBinder:               "Binder",
+	ErrorTypeNetwork:              "Network",
+	ErrorTypeOptimizer:            "Optimizer",
+	ErrorTypeNullPointer:          "NullPointer",
+	ErrorTypeIO:                   "IO",
+	ErrorTypeInterrupt:            "Interrupt",
+	ErrorTypeFatal:                "Fatal",
+	ErrorTypeInternal:             "Internal",
+	ErrorTypeInvalidInput:         "InvalidInput",
+	ErrorTypeOutOfMemory:          "OutOfMemory",
+	ErrorTypePermission:           "Permission",
+	ErrorTypeParameterNotResolved: "ParameterNotResolved",
+	ErrorTypeParameterNotAllowed:  "ParameterNotAllowed",
+	ErrorTypeDependency:           "Dependency",
+	ErrorTypeHTTP:                 "HTTP",
+	ErrorTypeMissingExtension:     "MissingExtension",
+	ErrorTypeAutoLoad:             "AutoLoad",
+	ErrorTypeSequence:             "Sequence",
+	ErrorTypeInvalidConfiguration: "InvalidConfiguration",
+}
+
+// String returns the name of the error type, e.g., "Catalog" for ErrorTypeCatalog.
+func (t ErrorType) String() string {
+	if name, ok := errorTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("ErrorType(%d)", int(t))
+}
+
 var errorPrefixMap = map[string]ErrorType{
 	"Invalid Error":                ErrorTypeInvalid,
 	"Out of Range Error":           ErrorTypeOutOfRange,
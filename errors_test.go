@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/marcboeker/go-duckdb/mapping"
 )
 
 func testErrorInternal(t *testing.T, actual error, contains []string) {
@@ -32,11 +34,29 @@ func TestErrConnect(t *testing.T) {
 	})
 
 	t.Run(errConnect.Error(), func(t *testing.T) {
-		db, err := sql.Open(`duckdb`, `?readonly`)
+		db, err := sql.Open(`duckdb`, `does/not/exist/foo.db`)
 		defer closeDbWrapper(t, db)
 		testError(t, err, errConnect.Error())
 	})
 
+	t.Run("unknown config option", func(t *testing.T) {
+		db, err := sql.Open(`duckdb`, `?thread=4`)
+		defer closeDbWrapper(t, db)
+		testError(t, err, errSetConfig.Error(), unknownConfigOptionErrMsg+": thread", suggestionsErrMsg+": threads")
+	})
+
+	t.Run("unknown config option without suggestions", func(t *testing.T) {
+		db, err := sql.Open(`duckdb`, `?readonly`)
+		defer closeDbWrapper(t, db)
+		testError(t, err, errSetConfig.Error(), unknownConfigOptionErrMsg+": readonly")
+	})
+
+	t.Run("unknown config option of Config", func(t *testing.T) {
+		c, err := NewConnectorWithConfig(``, Config{Options: map[string]string{"memory_limt": "1GB"}})
+		require.Nil(t, c)
+		testError(t, err, errSetConfig.Error(), suggestionsErrMsg+": memory_limit")
+	})
+
 	t.Run(errSetConfig.Error(), func(t *testing.T) {
 		db, err := sql.Open(`duckdb`, `?threads=NaN`)
 		defer closeDbWrapper(t, db)
@@ -50,6 +70,43 @@ func TestErrConnect(t *testing.T) {
 	})
 }
 
+func TestErrConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		msg    string
+	}{
+		{
+			name:   "negative threads",
+			config: Config{Threads: -1},
+			msg:    invalidInputErrMsg,
+		},
+		{
+			name:   "invalid access mode",
+			config: Config{AccessMode: "write_only"},
+			msg:    invalidInputErrMsg,
+		},
+		{
+			name:   "invalid default order",
+			config: Config{DefaultOrder: "up"},
+			msg:    invalidInputErrMsg,
+		},
+		{
+			name:   "duplicate option",
+			config: Config{Threads: 4, Options: map[string]string{"THREADS": "2"}},
+			msg:    duplicateNameErrMsg,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewConnectorWithConfig(``, tt.config)
+			require.Nil(t, c)
+			testError(t, err, errInvalidConfig.Error(), tt.msg)
+		})
+	}
+}
+
 func TestErrNestedMap(t *testing.T) {
 	db := openDbWrapper(t, ``)
 	defer closeDbWrapper(t, db)
@@ -534,3 +591,19 @@ func TestGetDuckDBErrorIs(t *testing.T) {
 	require.NotErrorIs(t, invalidInputErr, outOfRangeErr1)
 	require.NotErrorIs(t, errors.New(errMsg), outOfRangeErr1)
 }
+
+func TestErrorTypeDecimal(t *testing.T) {
+	// ErrorTypeDecimal is distinct from the other error types, e.g., ErrorTypeConstraint.
+	require.NotEqual(t, ErrorTypeConstraint, ErrorTypeDecimal)
+	require.Equal(t, "Decimal", ErrorTypeDecimal.String())
+
+	// DuckDB's decimal errors map to ErrorTypeDecimal.
+	err := errorDataError(mapping.CreateErrorData(mapping.ErrorTypeDecimal, "decimal error"))
+	var duckdbErr *Error
+	require.ErrorAs(t, err, &duckdbErr)
+	require.Equal(t, ErrorTypeDecimal, duckdbErr.Type)
+
+	err = getDuckDBError("Decimal Error: decimal error")
+	require.ErrorAs(t, err, &duckdbErr)
+	require.Equal(t, ErrorTypeDecimal, duckdbErr.Type)
+}
@@ -0,0 +1,171 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"time"
+)
+
+// StmtInfo describes a statement passed to Hooks.
+type StmtInfo struct {
+	// Query is the query of the statement. For multi-statement queries, it contains all statements.
+	Query string
+	// Args are the arguments of the statement. They are nil for statements executed via ExecBound or QueryBound.
+	Args []driver.NamedValue
+	// Type is the type of the statement.
+	Type StmtType
+	// ConnId is the id of the connection executing the statement.
+	ConnId uint64
+}
+
+// StmtResult describes the outcome of an executed statement.
+type StmtResult struct {
+	// Duration is the execution time of the statement, including binding its arguments.
+	Duration time.Duration
+	// RowsChanged is the number of rows changed by the statement.
+	RowsChanged int64
+	// Err is the error of the statement, if any.
+	Err error
+}
+
+// RowsResult describes the iteration of the rows returned by a statement.
+type RowsResult struct {
+	// Duration is the time between returning the rows and closing them.
+	Duration time.Duration
+	// Rows is the number of scanned rows.
+	Rows int64
+	// Chunks is the number of fetched data chunks.
+	Chunks int
+}
+
+// UDFCallInfo describes the execution of a user-defined function on a data chunk.
+type UDFCallInfo struct {
+	// Name is the name of the function.
+	Name string
+	// Table is true for table functions, and false for scalar functions.
+	Table bool
+	// Rows is the number of input rows of a scalar function, or the number of output rows of a table function.
+	Rows int
+	// Duration is the execution time of the function on the data chunk.
+	Duration time.Duration
+	// Err is the error of the function, if any.
+	Err error
+	// ConnId is the id of the connection executing the function.
+	ConnId uint64
+}
+
+// AppenderFlushInfo describes a flush of an Appender.
+type AppenderFlushInfo struct {
+	// Table is the qualified name of the table the Appender appends to. It is empty for query appenders.
+	Table string
+	// Rows is the number of rows appended since the previous flush.
+	Rows int
+	// Duration is the duration of the flush.
+	Duration time.Duration
+	// Err is the error of the flush, if any.
+	Err error
+	// ConnId is the id of the connection of the Appender.
+	ConnId uint64
+}
+
+// Hooks observe and intercept the statements and appenders of a Connector's connections.
+// Embed NoopHooks to implement only a subset of the hooks.
+// Hooks must be safe for concurrent use by multiple connections.
+//
+// The Connector calls AfterExecute exactly once for each call of BeforeExecute, and only then.
+// If a hook's BeforeExecute rejects a statement, then the hooks registered after it are neither
+// called before nor after executing the statement.
+type Hooks interface {
+	// BeforePrepare is called before preparing a query.
+	// It returns the query to prepare, or an error to reject the query.
+	BeforePrepare(ctx context.Context, query string) (string, error)
+	// BeforeExecute is called before binding the arguments and executing a prepared statement.
+	// It can modify the arguments in info, or return an error to reject the statement.
+	// The returned context is passed to the execution, to AfterExecute, to OnRowsClose, and to OnUDFCall.
+	BeforeExecute(ctx context.Context, info *StmtInfo) (context.Context, error)
+	// AfterExecute is called after executing a statement, including rejected and failed statements,
+	// if BeforeExecute was called for the statement.
+	AfterExecute(ctx context.Context, info StmtInfo, res StmtResult)
+	// OnRowsClose is called after closing the rows returned by a statement.
+	OnRowsClose(ctx context.Context, info StmtInfo, res RowsResult)
+	// OnUDFCall is called after executing a user-defined function on a data chunk.
+	// ctx is the context of the statement executing the function, if known.
+	OnUDFCall(ctx context.Context, info UDFCallInfo)
+	// OnAppenderFlush is called after an Appender flushes its rows, including when closing it.
+	OnAppenderFlush(info AppenderFlushInfo)
+}
+
+// NoopHooks implements Hooks without observing or modifying anything.
+type NoopHooks struct{}
+
+func (NoopHooks) BeforePrepare(_ context.Context, query string) (string, error) {
+	return query, nil
+}
+
+func (NoopHooks) BeforeExecute(ctx context.Context, _ *StmtInfo) (context.Context, error) {
+	return ctx, nil
+}
+
+func (NoopHooks) AfterExecute(context.Context, StmtInfo, StmtResult) {}
+
+func (NoopHooks) OnRowsClose(context.Context, StmtInfo, RowsResult) {}
+
+func (NoopHooks) OnUDFCall(context.Context, UDFCallInfo) {}
+
+func (NoopHooks) OnAppenderFlush(AppenderFlushInfo) {}
+
+// WithHooks registers hooks on the Connector.
+// The Connector calls multiple hooks in the order of their registration.
+func WithHooks(hooks ...Hooks) ConnectorOption {
+	return func(opts *connectorOptions) {
+		opts.hooks = append(opts.hooks, hooks...)
+	}
+}
+
+func (c *Connector) beforePrepare(ctx context.Context, query string) (string, error) {
+	for _, h := range c.hooks {
+		var err error
+		if query, err = h.BeforePrepare(ctx, query); err != nil {
+			return "", err
+		}
+	}
+	return query, nil
+}
+
+// beforeExecute calls the BeforeExecute hooks until a hook rejects the statement.
+// It returns the number of called hooks, which is the number of hooks to call afterExecute for.
+func (c *Connector) beforeExecute(ctx context.Context, info *StmtInfo) (context.Context, int, error) {
+	for i, h := range c.hooks {
+		hookCtx, err := h.BeforeExecute(ctx, info)
+		if err != nil {
+			return ctx, i + 1, err
+		}
+		ctx = hookCtx
+	}
+	return ctx, len(c.hooks), nil
+}
+
+// afterExecute calls the AfterExecute hooks of the first n hooks, whose BeforeExecute hooks were called.
+func (c *Connector) afterExecute(ctx context.Context, n int, info StmtInfo, res StmtResult) {
+	for _, h := range c.hooks[:n] {
+		h.AfterExecute(ctx, info, res)
+	}
+}
+
+func (c *Connector) onRowsClose(ctx context.Context, info StmtInfo, res RowsResult) {
+	for _, h := range c.hooks {
+		h.OnRowsClose(ctx, info, res)
+	}
+}
+
+func (c *Connector) onUDFCall(ctx context.Context, info UDFCallInfo) {
+	for _, h := range c.hooks {
+		h.OnUDFCall(ctx, info)
+	}
+}
+
+func (c *Connector) onAppenderFlush(info AppenderFlushInfo) {
+	for _, h := range c.hooks {
+		h.OnAppenderFlush(info)
+	}
+}
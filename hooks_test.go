@@ -0,0 +1,233 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordingHooks struct {
+	NoopHooks
+	mu        sync.Mutex
+	prepared  []string
+	executed  []StmtInfo
+	results   []StmtResult
+	rows      []RowsResult
+	udfCalls  []UDFCallInfo
+	flushes   []AppenderFlushInfo
+	rejectErr error
+}
+
+type hookCtxKey struct{}
+
+func (h *recordingHooks) BeforePrepare(_ context.Context, query string) (string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.prepared = append(h.prepared, query)
+	return strings.ReplaceAll(query, "old_table", "new_table"), nil
+}
+
+func (h *recordingHooks) BeforeExecute(ctx context.Context, info *StmtInfo) (context.Context, error) {
+	if strings.Contains(info.Query, "forbidden") {
+		return ctx, h.rejectErr
+	}
+	// Double all integer arguments.
+	for i, arg := range info.Args {
+		if v, ok := arg.Value.(int64); ok {
+			info.Args[i].Value = 2 * v
+		}
+	}
+	return context.WithValue(ctx, hookCtxKey{}, info.Query), nil
+}
+
+func (h *recordingHooks) AfterExecute(_ context.Context, info StmtInfo, res StmtResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.executed = append(h.executed, info)
+	h.results = append(h.results, res)
+}
+
+func (h *recordingHooks) OnRowsClose(ctx context.Context, info StmtInfo, res RowsResult) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ctx.Value(hookCtxKey{}) == info.Query {
+		h.rows = append(h.rows, res)
+	}
+}
+
+func (h *recordingHooks) OnUDFCall(ctx context.Context, info UDFCallInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if ctx.Value(hookCtxKey{}) != nil {
+		h.udfCalls = append(h.udfCalls, info)
+	}
+}
+
+func (h *recordingHooks) OnAppenderFlush(info AppenderFlushInfo) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.flushes = append(h.flushes, info)
+}
+
+func TestHooks(t *testing.T) {
+	hooks := &recordingHooks{rejectErr: errors.New("rejected")}
+	c, err := NewConnectorWithConfig(``, Config{}, WithHooks(hooks))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	db.SetMaxOpenConns(1)
+
+	t.Run("exec", func(t *testing.T) {
+		createTable(t, db, `CREATE TABLE new_table (i BIGINT)`)
+		res, err := db.Exec(`INSERT INTO old_table VALUES (?), (?)`, int64(1), int64(2))
+		require.NoError(t, err)
+		ra, err := res.RowsAffected()
+		require.NoError(t, err)
+		require.Equal(t, int64(2), ra)
+
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		require.Contains(t, hooks.prepared, `INSERT INTO old_table VALUES (?), (?)`)
+
+		last := hooks.executed[len(hooks.executed)-1]
+		require.Equal(t, `INSERT INTO new_table VALUES (?), (?)`, last.Query)
+		require.Equal(t, STATEMENT_TYPE_INSERT, last.Type)
+		require.Len(t, last.Args, 2)
+
+		lastRes := hooks.results[len(hooks.results)-1]
+		require.NoError(t, lastRes.Err)
+		require.Equal(t, int64(2), lastRes.RowsChanged)
+		require.Positive(t, lastRes.Duration)
+	})
+
+	t.Run("modified arguments", func(t *testing.T) {
+		var sum int64
+		require.NoError(t, db.QueryRow(`SELECT sum(i) FROM new_table`).Scan(&sum))
+		require.Equal(t, int64(6), sum)
+	})
+
+	t.Run("rows", func(t *testing.T) {
+		r, err := db.Query(`SELECT range FROM range(5000)`)
+		require.NoError(t, err)
+		for r.Next() {
+		}
+		require.NoError(t, r.Close())
+
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		require.NotEmpty(t, hooks.rows)
+		res := hooks.rows[len(hooks.rows)-1]
+		require.Equal(t, int64(5000), res.Rows)
+		require.Equal(t, 3, res.Chunks)
+	})
+
+	t.Run("udf calls", func(t *testing.T) {
+		conn := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, conn)
+
+		var err error
+		currentInfo, err = NewTypeInfo(TYPE_INTEGER)
+		require.NoError(t, err)
+
+		var udf *simpleSUDF
+		require.NoError(t, RegisterScalarUDF(conn, "my_sum", udf))
+		var tudf *incTableUDF
+		require.NoError(t, RegisterTableUDF(conn, "my_range", tudf.GetFunction()))
+
+		var sum int64
+		require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT sum(my_sum(i::INTEGER, 1)) FROM range(3) t(i)`).Scan(&sum))
+		require.Equal(t, int64(6), sum)
+
+		var count int64
+		require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT count(*) FROM my_range(10)`).Scan(&count))
+		require.Equal(t, int64(10), count)
+
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		require.NotEmpty(t, hooks.udfCalls)
+		require.Equal(t, "my_sum", hooks.udfCalls[0].Name)
+		require.False(t, hooks.udfCalls[0].Table)
+		require.Equal(t, 3, hooks.udfCalls[0].Rows)
+
+		var tableRows int
+		for _, call := range hooks.udfCalls[1:] {
+			require.Equal(t, "my_range", call.Name)
+			require.True(t, call.Table)
+			require.NoError(t, call.Err)
+			tableRows += call.Rows
+		}
+		require.Equal(t, 10, tableRows)
+	})
+
+	t.Run("rejected statement", func(t *testing.T) {
+		_, err := db.Exec(`SELECT 'forbidden'`)
+		require.ErrorIs(t, err, hooks.rejectErr)
+
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		require.ErrorIs(t, hooks.results[len(hooks.results)-1].Err, hooks.rejectErr)
+	})
+
+	t.Run("failed statement", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO new_table VALUES ('not a number')`)
+		require.Error(t, err)
+
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		require.Error(t, hooks.results[len(hooks.results)-1].Err)
+	})
+
+	t.Run("appender flush", func(t *testing.T) {
+		conn := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, conn)
+
+		require.NoError(t, conn.Raw(func(driverConn any) error {
+			a, err := NewAppenderFromConn(driverConn.(driver.Conn), "", "new_table")
+			require.NoError(t, err)
+			for i := range 3 {
+				require.NoError(t, a.AppendRow(int64(i)))
+			}
+			require.NoError(t, a.Flush())
+			require.NoError(t, a.AppendRow(int64(42)))
+			return a.Close()
+		}))
+
+		hooks.mu.Lock()
+		defer hooks.mu.Unlock()
+		require.Len(t, hooks.flushes, 2)
+		require.Equal(t, "new_table", hooks.flushes[0].Table)
+		require.Equal(t, 3, hooks.flushes[0].Rows)
+		require.Equal(t, 1, hooks.flushes[1].Rows)
+		require.NoError(t, hooks.flushes[1].Err)
+	})
+}
+
+func TestHooksRejectedBeforeExecute(t *testing.T) {
+	first := &recordingHooks{rejectErr: errors.New("rejected")}
+	second := &recordingHooks{}
+	c, err := NewConnectorWithConfig(``, Config{}, WithHooks(first, second))
+	require.NoError(t, err)
+	defer closeConnectorWrapper(t, c)
+
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	_, err = db.Exec(`SELECT 'forbidden'`)
+	require.ErrorIs(t, err, first.rejectErr)
+
+	// Only the rejecting hook, whose BeforeExecute ran, observes the rejected statement.
+	require.Len(t, first.results, 1)
+	require.ErrorIs(t, first.results[0].Err, first.rejectErr)
+	require.Empty(t, second.results)
+
+	_, err = db.Exec(`SELECT 42`)
+	require.NoError(t, err)
+	require.Len(t, first.results, 2)
+	require.Len(t, second.results, 1)
+}
@@ -0,0 +1,182 @@
+package duckdb
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// GetInstanceCache returns the instance cache of all file-backed and named in-memory databases.
+// The instance cache ensures that Connectors opening the same path share the same database.
+var GetInstanceCache = func() mapping.InstanceCache {
+	return instances.get()
+}
+
+// instances tracks the Connectors of all databases opened through the instance cache.
+var instances = &instanceCache{
+	connectors: make(map[string]map[*Connector]struct{}),
+}
+
+type instanceCache struct {
+	mu sync.Mutex
+	// The DuckDB instance cache. It is created lazily.
+	cache mapping.InstanceCache
+	// The open Connectors by (absolute) database path.
+	connectors map[string]map[*Connector]struct{}
+}
+
+func (ic *instanceCache) get() mapping.InstanceCache {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+	return ic.getLocked()
+}
+
+func (ic *instanceCache) getLocked() mapping.InstanceCache {
+	if ic.cache.Ptr == nil {
+		ic.cache = mapping.CreateInstanceCache()
+	}
+	return ic.cache
+}
+
+// open opens the database at path through the instance cache and tracks the Connector.
+func (ic *instanceCache) open(c *Connector, path string, config mapping.Config, errMsg *string) mapping.State {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	state := mapping.GetOrCreateFromCache(ic.getLocked(), path, &c.db, config, errMsg)
+	if state == mapping.StateError {
+		return state
+	}
+
+	c.cachePath = cacheKey(path)
+	if ic.connectors[c.cachePath] == nil {
+		ic.connectors[c.cachePath] = make(map[*Connector]struct{})
+	}
+	ic.connectors[c.cachePath][c] = struct{}{}
+
+	return state
+}
+
+// release stops tracking the Connector.
+func (ic *instanceCache) release(c *Connector) {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	connectors := ic.connectors[c.cachePath]
+	delete(connectors, c)
+	if len(connectors) == 0 {
+		delete(ic.connectors, c.cachePath)
+	}
+}
+
+// connectorsOf returns the open Connectors of the database at the (absolute) path.
+func (ic *instanceCache) connectorsOf(path string) []*Connector {
+	ic.mu.Lock()
+	defer ic.mu.Unlock()
+
+	connectors := make([]*Connector, 0, len(ic.connectors[path]))
+	for c := range ic.connectors[path] {
+		connectors = append(connectors, c)
+	}
+	return connectors
+}
+
+// executingConnector returns the Connector of the connection with the id,
+// which executes a user-defined function registered through c.
+// Connectors opening the same path share their database and its functions,
+// so the executing connection can belong to another Connector than c.
+// It returns c, if no open Connector has a connection with the id.
+func (c *Connector) executingConnector(connId uint64) *Connector {
+	if c.cachePath == "" || c.connById(connId) != nil {
+		return c
+	}
+	// Do not hold the lock of the instance cache while locking a Connector,
+	// as closing a Connector locks it before locking the instance cache.
+	for _, other := range instances.connectorsOf(c.cachePath) {
+		if other.connById(connId) != nil {
+			return other
+		}
+	}
+	return c
+}
+
+// cacheKey returns the key of a database path.
+// DuckDB resolves relative paths, so we do, too.
+func cacheKey(path string) string {
+	if strings.HasPrefix(path, inMemoryName) {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// CachedDatabase describes a database opened through the instance cache.
+type CachedDatabase struct {
+	// Path is the absolute path of the database, or the name of a named in-memory database.
+	Path string
+	// Connectors is the number of open Connectors referencing the database.
+	Connectors int
+}
+
+// CachedDatabases returns all databases opened through the instance cache, sorted by path.
+// A database is released once all of its Connectors and their connections are closed.
+func CachedDatabases() []CachedDatabase {
+	instances.mu.Lock()
+	defer instances.mu.Unlock()
+
+	dbs := make([]CachedDatabase, 0, len(instances.connectors))
+	for path, connectors := range instances.connectors {
+		dbs = append(dbs, CachedDatabase{Path: path, Connectors: len(connectors)})
+	}
+	slices.SortFunc(dbs, func(a, b CachedDatabase) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return dbs
+}
+
+// EvictCachedDatabase closes all Connectors referencing the database at path.
+// Afterward, these Connectors fail to create new connections.
+// DuckDB releases the database once all existing connections to it are closed.
+// Opening the path again creates a new database instance.
+func EvictCachedDatabase(path string) error {
+	instances.mu.Lock()
+	connectors := instances.connectors[cacheKey(path)]
+	toClose := make([]*Connector, 0, len(connectors))
+	for c := range connectors {
+		toClose = append(toClose, c)
+	}
+	instances.mu.Unlock()
+
+	if len(toClose) == 0 {
+		return getError(errAPI, notCachedError(path))
+	}
+
+	for _, c := range toClose {
+		if err := c.Close(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DestroyInstanceCache destroys the instance cache.
+// It fails, if Connectors referencing cached databases are still open. Close or evict them first.
+// Subsequent Connectors use a new instance cache.
+func DestroyInstanceCache() error {
+	instances.mu.Lock()
+	defer instances.mu.Unlock()
+
+	if len(instances.connectors) != 0 {
+		return getError(errAPI, errInstanceCacheInUse)
+	}
+	mapping.DestroyInstanceCache(&instances.cache)
+	instances.connectors = make(map[string]map[*Connector]struct{})
+	return nil
+}
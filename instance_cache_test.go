@@ -0,0 +1,105 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func cachedConnectors(path string) int {
+	for _, db := range CachedDatabases() {
+		if db.Path == path {
+			return db.Connectors
+		}
+	}
+	return 0
+}
+
+func TestCachedDatabases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cached.db")
+
+	c1 := newConnectorWrapper(t, path, nil)
+	require.Equal(t, 1, cachedConnectors(path))
+
+	c2 := newConnectorWrapper(t, path, nil)
+	require.Equal(t, 2, cachedConnectors(path))
+
+	c3 := newConnectorWrapper(t, `:memory:cached`, nil)
+	require.Equal(t, 1, cachedConnectors(`:memory:cached`))
+
+	// Unnamed in-memory databases do not use the instance cache.
+	c4 := newConnectorWrapper(t, ``, nil)
+	closeConnectorWrapper(t, c4)
+
+	closeConnectorWrapper(t, c1)
+	require.Equal(t, 1, cachedConnectors(path))
+	closeConnectorWrapper(t, c2)
+	require.Equal(t, 0, cachedConnectors(path))
+	closeConnectorWrapper(t, c3)
+	require.Equal(t, 0, cachedConnectors(`:memory:cached`))
+}
+
+func TestEvictCachedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "evict.db")
+
+	c1 := newConnectorWrapper(t, path, nil)
+	defer closeConnectorWrapper(t, c1)
+	c2 := newConnectorWrapper(t, path, nil)
+	defer closeConnectorWrapper(t, c2)
+
+	db := sql.OpenDB(c1)
+	createTable(t, db, `CREATE TABLE tbl (i INTEGER)`)
+	require.NoError(t, db.Close())
+
+	require.NoError(t, EvictCachedDatabase(path))
+	require.Equal(t, 0, cachedConnectors(path))
+
+	// The evicted Connectors cannot create new connections.
+	_, err := c2.Connect(context.Background())
+	testError(t, err, errConnect.Error(), errClosedCon.Error())
+
+	// Evicting an unknown path fails.
+	err = EvictCachedDatabase(path)
+	testError(t, err, errAPI.Error(), notCachedErrMsg)
+
+	// The file is released, so that we can open it without the instance cache.
+	c3, err := NewConnectorWithConfig(path, Config{}, WithoutInstanceCache())
+	require.NoError(t, err)
+	defer closeConnectorWrapper(t, c3)
+	require.Equal(t, 0, cachedConnectors(path))
+
+	db = sql.OpenDB(c3)
+	defer closeDbWrapper(t, db)
+	_, err = db.Exec(`SELECT * FROM tbl`)
+	require.NoError(t, err)
+}
+
+func TestDestroyInstanceCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "destroy.db")
+
+	c := newConnectorWrapper(t, path, nil)
+	db := sql.OpenDB(c)
+	createTable(t, db, `CREATE TABLE tbl (i INTEGER)`)
+
+	// Destroying the cache fails while Connectors are open.
+	err := DestroyInstanceCache()
+	testError(t, err, errAPI.Error(), errInstanceCacheInUse.Error())
+	require.Equal(t, 1, cachedConnectors(path))
+
+	closeDbWrapper(t, db)
+	closeConnectorWrapper(t, c)
+	require.NoError(t, DestroyInstanceCache())
+	require.Empty(t, CachedDatabases())
+
+	// New Connectors use a new instance cache.
+	c = newConnectorWrapper(t, path, nil)
+	defer closeConnectorWrapper(t, c)
+	require.Equal(t, 1, cachedConnectors(path))
+	db = sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	_, err = db.Exec(`SELECT * FROM tbl`)
+	require.NoError(t, err)
+}
@@ -0,0 +1,99 @@
+package duckdb
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"strings"
+	"time"
+)
+
+const (
+	defaultLockInitialBackoff = 50 * time.Millisecond
+	defaultLockMaxBackoff     = 2 * time.Second
+
+	// lockErrMsg is the prefix of DuckDB's error message when another process holds the lock on a database file.
+	lockErrMsg = "Could not set lock on file"
+)
+
+// LockWaitOptions configure how a Connector waits for a database file locked by another process.
+type LockWaitOptions struct {
+	// InitialBackoff is the duration to wait before the first retry. It defaults to 50ms.
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum duration between two retries. The backoff doubles after each retry,
+	// until it reaches MaxBackoff. It defaults to 2s.
+	MaxBackoff time.Duration
+	// ReadOnlyFallback opens the database in read-only mode, if the lock is still held
+	// once the context is done. Read-only mode succeeds, if the other process also opened the file read-only.
+	ReadOnlyFallback bool
+}
+
+type lockWait struct {
+	ctx context.Context
+	LockWaitOptions
+}
+
+// WithLockWait retries opening a database file while another process holds its lock.
+// The Connector retries with an exponential backoff until it obtains the lock, or until ctx is done.
+// Connector.AccessMode reports the access mode the Connector obtained.
+func WithLockWait(ctx context.Context, opts LockWaitOptions) ConnectorOption {
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = defaultLockInitialBackoff
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = defaultLockMaxBackoff
+	}
+	return func(connectorOpts *connectorOptions) {
+		connectorOpts.lockWait = &lockWait{ctx: ctx, LockWaitOptions: opts}
+	}
+}
+
+// openWithLockWait opens the database at path, and retries while another process holds its lock.
+func (c *Connector) openWithLockWait(path string, options map[string]string, opts connectorOptions) error {
+	wait := opts.lockWait
+	backoff := wait.InitialBackoff
+
+	err := c.open(path, options, opts)
+	for err != nil && isLockError(err) {
+		timer := time.NewTimer(backoff)
+		select {
+		case <-wait.ctx.Done():
+			timer.Stop()
+			if !wait.ReadOnlyFallback || c.accessMode == AccessModeReadOnly {
+				return errors.Join(wait.ctx.Err(), err)
+			}
+			return c.openReadOnly(path, options, opts)
+		case <-timer.C:
+		}
+
+		err = c.open(path, options, opts)
+		backoff = min(2*backoff, wait.MaxBackoff)
+	}
+
+	return err
+}
+
+// openReadOnly opens the database at path in read-only mode.
+func (c *Connector) openReadOnly(path string, options map[string]string, opts connectorOptions) error {
+	readOnlyOptions := maps.Clone(options)
+	if readOnlyOptions == nil {
+		readOnlyOptions = make(map[string]string)
+	}
+	for k := range readOnlyOptions {
+		if strings.EqualFold(k, "access_mode") {
+			delete(readOnlyOptions, k)
+		}
+	}
+	readOnlyOptions["access_mode"] = string(AccessModeReadOnly)
+
+	if err := c.open(path, readOnlyOptions, opts); err != nil {
+		return err
+	}
+	c.accessMode = AccessModeReadOnly
+
+	return nil
+}
+
+func isLockError(err error) bool {
+	return strings.Contains(err.Error(), lockErrMsg)
+}
@@ -0,0 +1,121 @@
+package duckdb
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const lockHelperEnv = "GO_DUCKDB_LOCK_HELPER_DSN"
+
+// TestLockHelperProcess is not a real test. It holds the lock on a database file
+// on behalf of another test, until its stdin is closed.
+func TestLockHelperProcess(t *testing.T) {
+	dsn := os.Getenv(lockHelperEnv)
+	if dsn == "" {
+		t.Skip("helper process")
+	}
+
+	db := openDbWrapper(t, dsn)
+	defer closeDbWrapper(t, db)
+
+	_, err := os.Stdout.WriteString("locked\n")
+	require.NoError(t, err)
+
+	// Wait until the parent closes stdin.
+	_, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+}
+
+// holdLock starts a process holding the lock on a database file.
+// Calling the returned function releases the lock.
+func holdLock(t *testing.T, dsn string) func() {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+	cmd.Env = append(os.Environ(), lockHelperEnv+"="+dsn)
+	stdin, err := cmd.StdinPipe()
+	require.NoError(t, err)
+	stdout, err := cmd.StdoutPipe()
+	require.NoError(t, err)
+	require.NoError(t, cmd.Start())
+
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "locked\n", line)
+
+	return func() {
+		require.NoError(t, stdin.Close())
+		require.NoError(t, cmd.Wait())
+	}
+}
+
+func TestLockWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lock.db")
+	db := openDbWrapper(t, path)
+	createTable(t, db, `CREATE TABLE tbl AS SELECT 42 AS i`)
+	closeDbWrapper(t, db)
+
+	t.Run("without waiting", func(t *testing.T) {
+		release := holdLock(t, path)
+		defer release()
+
+		_, err := NewConnectorWithConfig(path, Config{})
+		testError(t, err, errConnect.Error(), lockErrMsg)
+	})
+
+	t.Run("deadline exceeded", func(t *testing.T) {
+		release := holdLock(t, path)
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		_, err := NewConnectorWithConfig(path, Config{}, WithLockWait(ctx, LockWaitOptions{}))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, lockErrMsg)
+	})
+
+	t.Run("lock released", func(t *testing.T) {
+		release := holdLock(t, path)
+		go func() {
+			time.Sleep(200 * time.Millisecond)
+			release()
+		}()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		c, err := NewConnectorWithConfig(path, Config{}, WithLockWait(ctx, LockWaitOptions{
+			InitialBackoff: 10 * time.Millisecond,
+			MaxBackoff:     50 * time.Millisecond,
+		}))
+		require.NoError(t, err)
+		defer closeConnectorWrapper(t, c)
+		require.Equal(t, AccessModeReadWrite, c.AccessMode())
+	})
+
+	t.Run("read-only fallback", func(t *testing.T) {
+		release := holdLock(t, path+"?access_mode=read_only")
+		defer release()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		c, err := NewConnectorWithConfig(path, Config{}, WithLockWait(ctx, LockWaitOptions{ReadOnlyFallback: true}))
+		require.NoError(t, err)
+		defer closeConnectorWrapper(t, c)
+		require.Equal(t, AccessModeReadOnly, c.AccessMode())
+
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+
+		var i int
+		require.NoError(t, db.QueryRow(`SELECT i FROM tbl`).Scan(&i))
+		require.Equal(t, 42, i)
+	})
+}
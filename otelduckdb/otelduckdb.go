@@ -0,0 +1,308 @@
+// Package otelduckdb emits OpenTelemetry spans and metrics for the go-duckdb driver.
+//
+// Register the Hooks of this package on a duckdb.Connector:
+//
+//	hooks, err := otelduckdb.NewHooks()
+//	...
+//	c, err := duckdb.NewConnectorWithConfig("my.db", duckdb.Config{}, duckdb.WithHooks(hooks))
+//
+// Each executed statement creates a client span, which is a child of the span in the context passed to
+// ExecContext or QueryContext. Iterating the returned rows creates a child span of the statement's span.
+// User-defined functions and appender flushes emit metrics.
+package otelduckdb
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/marcboeker/go-duckdb/v2"
+)
+
+// ScopeName is the instrumentation scope name of the tracer and meter.
+const ScopeName = "github.com/marcboeker/go-duckdb/otelduckdb"
+
+// The attribute keys of the spans and metrics.
+const (
+	dbSystemNameKey    = attribute.Key("db.system.name")
+	dbOperationNameKey = attribute.Key("db.operation.name")
+	dbQueryTextKey     = attribute.Key("db.query.text")
+	errorTypeKey       = attribute.Key("error.type")
+	exceptionMsgKey    = attribute.Key("exception.message")
+	connIdKey          = attribute.Key("duckdb.connection.id")
+	rowsChangedKey     = attribute.Key("duckdb.rows_changed")
+	rowsKey            = attribute.Key("duckdb.rows")
+	chunksKey          = attribute.Key("duckdb.chunks")
+	udfNameKey         = attribute.Key("duckdb.udf.name")
+	udfKindKey         = attribute.Key("duckdb.udf.kind")
+	appenderTableKey   = attribute.Key("duckdb.appender.table")
+)
+
+const (
+	systemName           = "duckdb"
+	defaultOperationName = "DUCKDB"
+	errorTypeOther       = "_OTHER"
+	udfKindScalar        = "scalar"
+	udfKindTable         = "table"
+	rowsSpanName         = "duckdb.rows"
+	udfErrorEventName    = "duckdb.udf.error"
+)
+
+// The names of the metrics.
+const (
+	operationDurationName = "db.client.operation.duration"
+	rowsChangedName       = "duckdb.statement.rows_changed"
+	rowsScannedName       = "duckdb.rows.scanned"
+	chunksName            = "duckdb.rows.chunks"
+	udfDurationName       = "duckdb.udf.duration"
+	udfRowsName           = "duckdb.udf.rows"
+	appenderDurationName  = "duckdb.appender.flush.duration"
+	appenderRowsName      = "duckdb.appender.rows"
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	attrs          []attribute.KeyValue
+	queryText      bool
+}
+
+// Option configures the Hooks.
+type Option func(*config)
+
+// WithTracerProvider sets the TracerProvider. It defaults to the global TracerProvider.
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(cfg *config) {
+		cfg.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider sets the MeterProvider. It defaults to the global MeterProvider.
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(cfg *config) {
+		cfg.meterProvider = mp
+	}
+}
+
+// WithAttributes adds attributes to all spans and metrics, e.g., the name of the database.
+func WithAttributes(attrs ...attribute.KeyValue) Option {
+	return func(cfg *config) {
+		cfg.attrs = append(cfg.attrs, attrs...)
+	}
+}
+
+// WithoutQueryText omits the query text from spans.
+// Use it, if queries contain sensitive literals instead of parameters.
+func WithoutQueryText() Option {
+	return func(cfg *config) {
+		cfg.queryText = false
+	}
+}
+
+// Hooks implements duckdb.Hooks and emits OpenTelemetry spans and metrics.
+type Hooks struct {
+	tracer    trace.Tracer
+	attrs     []attribute.KeyValue
+	queryText bool
+
+	operationDuration metric.Float64Histogram
+	rowsChanged       metric.Int64Counter
+	rowsScanned       metric.Int64Counter
+	chunks            metric.Int64Counter
+	udfDuration       metric.Float64Histogram
+	udfRows           metric.Int64Counter
+	appenderDuration  metric.Float64Histogram
+	appenderRows      metric.Int64Counter
+}
+
+var _ duckdb.Hooks = (*Hooks)(nil)
+
+// NewHooks creates Hooks emitting spans and metrics with the configured providers.
+func NewHooks(opts ...Option) (*Hooks, error) {
+	cfg := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+		queryText:      true,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	h := Hooks{
+		tracer:    cfg.tracerProvider.Tracer(ScopeName),
+		attrs:     append([]attribute.KeyValue{dbSystemNameKey.String(systemName)}, cfg.attrs...),
+		queryText: cfg.queryText,
+	}
+
+	meter := cfg.meterProvider.Meter(ScopeName)
+	var err, e error
+
+	h.operationDuration, e = meter.Float64Histogram(operationDurationName,
+		metric.WithDescription("Duration of executing statements."), metric.WithUnit("s"))
+	err = errors.Join(err, e)
+	h.rowsChanged, e = meter.Int64Counter(rowsChangedName,
+		metric.WithDescription("Number of rows changed by statements."), metric.WithUnit("{row}"))
+	err = errors.Join(err, e)
+	h.rowsScanned, e = meter.Int64Counter(rowsScannedName,
+		metric.WithDescription("Number of rows scanned from results."), metric.WithUnit("{row}"))
+	err = errors.Join(err, e)
+	h.chunks, e = meter.Int64Counter(chunksName,
+		metric.WithDescription("Number of data chunks fetched from results."), metric.WithUnit("{chunk}"))
+	err = errors.Join(err, e)
+	h.udfDuration, e = meter.Float64Histogram(udfDurationName,
+		metric.WithDescription("Duration of executing user-defined functions on data chunks."), metric.WithUnit("s"))
+	err = errors.Join(err, e)
+	h.udfRows, e = meter.Int64Counter(udfRowsName,
+		metric.WithDescription("Number of rows processed by user-defined functions."), metric.WithUnit("{row}"))
+	err = errors.Join(err, e)
+	h.appenderDuration, e = meter.Float64Histogram(appenderDurationName,
+		metric.WithDescription("Duration of appender flushes."), metric.WithUnit("s"))
+	err = errors.Join(err, e)
+	h.appenderRows, e = meter.Int64Counter(appenderRowsName,
+		metric.WithDescription("Number of rows flushed by appenders."), metric.WithUnit("{row}"))
+	err = errors.Join(err, e)
+
+	if err != nil {
+		return nil, err
+	}
+	return &h, nil
+}
+
+// spanKey is the context key of the statement span started by a Hooks' BeforeExecute.
+type spanKey struct {
+	hooks *Hooks
+}
+
+// statementSpan returns the statement span started by h, or false, if h did not start a span in ctx.
+func (h *Hooks) statementSpan(ctx context.Context) (trace.Span, bool) {
+	span, ok := ctx.Value(spanKey{hooks: h}).(trace.Span)
+	return span, ok
+}
+
+// BeforePrepare implements duckdb.Hooks.
+func (h *Hooks) BeforePrepare(_ context.Context, query string) (string, error) {
+	return query, nil
+}
+
+// BeforeExecute implements duckdb.Hooks. It starts the span of the statement.
+func (h *Hooks) BeforeExecute(ctx context.Context, info *duckdb.StmtInfo) (context.Context, error) {
+	attrs := append(h.operationAttrs(info.Type), connIdKey.String(strconv.FormatUint(info.ConnId, 10)))
+	if h.queryText {
+		attrs = append(attrs, dbQueryTextKey.String(info.Query))
+	}
+
+	ctx, span := h.tracer.Start(ctx, operationName(info.Type),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+	return context.WithValue(ctx, spanKey{hooks: h}, span), nil
+}
+
+// AfterExecute implements duckdb.Hooks. It ends the span of the statement and records its metrics.
+func (h *Hooks) AfterExecute(ctx context.Context, info duckdb.StmtInfo, res duckdb.StmtResult) {
+	attrs := h.operationAttrs(info.Type)
+	if res.Err != nil {
+		attrs = append(attrs, errorTypeKey.String(errorType(res.Err)))
+	}
+
+	// Only end the span started by BeforeExecute, and never a span of the caller.
+	if span, ok := h.statementSpan(ctx); ok {
+		span.SetAttributes(rowsChangedKey.Int64(res.RowsChanged))
+		setSpanError(span, res.Err)
+		span.End()
+	}
+
+	h.operationDuration.Record(ctx, res.Duration.Seconds(), metric.WithAttributes(attrs...))
+	if res.RowsChanged != 0 {
+		h.rowsChanged.Add(ctx, res.RowsChanged, metric.WithAttributes(h.operationAttrs(info.Type)...))
+	}
+}
+
+// OnRowsClose implements duckdb.Hooks. It creates a span for iterating the rows of a statement.
+func (h *Hooks) OnRowsClose(ctx context.Context, info duckdb.StmtInfo, res duckdb.RowsResult) {
+	attrs := h.operationAttrs(info.Type)
+
+	end := time.Now()
+	_, span := h.tracer.Start(ctx, rowsSpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(end.Add(-res.Duration)),
+		trace.WithAttributes(attrs...))
+	span.SetAttributes(rowsKey.Int64(res.Rows), chunksKey.Int(res.Chunks))
+	span.End(trace.WithTimestamp(end))
+
+	h.rowsScanned.Add(ctx, res.Rows, metric.WithAttributes(attrs...))
+	h.chunks.Add(ctx, int64(res.Chunks), metric.WithAttributes(attrs...))
+}
+
+// OnUDFCall implements duckdb.Hooks. It records the metrics of a user-defined function.
+// Errors are additionally recorded as events of the statement's span.
+func (h *Hooks) OnUDFCall(ctx context.Context, info duckdb.UDFCallInfo) {
+	kind := udfKindScalar
+	if info.Table {
+		kind = udfKindTable
+	}
+	attrs := append(h.baseAttrs(), udfNameKey.String(info.Name), udfKindKey.String(kind))
+
+	h.udfRows.Add(ctx, int64(info.Rows), metric.WithAttributes(attrs...))
+	if info.Err != nil {
+		attrs = append(attrs, errorTypeKey.String(errorType(info.Err)))
+		if span, ok := h.statementSpan(ctx); ok {
+			span.AddEvent(udfErrorEventName, trace.WithAttributes(
+				udfNameKey.String(info.Name),
+				exceptionMsgKey.String(info.Err.Error())))
+		}
+	}
+	h.udfDuration.Record(ctx, info.Duration.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// OnAppenderFlush implements duckdb.Hooks. It records the metrics of an appender flush.
+func (h *Hooks) OnAppenderFlush(info duckdb.AppenderFlushInfo) {
+	ctx := context.Background()
+	attrs := append(h.baseAttrs(), appenderTableKey.String(info.Table))
+
+	h.appenderRows.Add(ctx, int64(info.Rows), metric.WithAttributes(attrs...))
+	if info.Err != nil {
+		attrs = append(attrs, errorTypeKey.String(errorType(info.Err)))
+	}
+	h.appenderDuration.Record(ctx, info.Duration.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// baseAttrs returns a copy of the attributes of all spans and metrics.
+func (h *Hooks) baseAttrs() []attribute.KeyValue {
+	return append([]attribute.KeyValue(nil), h.attrs...)
+}
+
+func (h *Hooks) operationAttrs(t duckdb.StmtType) []attribute.KeyValue {
+	return append(h.baseAttrs(), dbOperationNameKey.String(operationName(t)))
+}
+
+func operationName(t duckdb.StmtType) string {
+	if t == duckdb.STATEMENT_TYPE_INVALID {
+		return defaultOperationName
+	}
+	return t.String()
+}
+
+// errorType returns the duckdb.ErrorType of err, or "_OTHER" for other errors.
+func errorType(err error) string {
+	var duckdbErr *duckdb.Error
+	if errors.As(err, &duckdbErr) {
+		return duckdbErr.Type.String()
+	}
+	return errorTypeOther
+}
+
+func setSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.SetAttributes(errorTypeKey.String(errorType(err)))
+}
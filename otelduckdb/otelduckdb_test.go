@@ -0,0 +1,247 @@
+package otelduckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/marcboeker/go-duckdb/v2"
+)
+
+func setup(t *testing.T) (*sql.DB, *tracetest.SpanRecorder, *sdkmetric.ManualReader) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	reader := sdkmetric.NewManualReader()
+	mp := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+
+	hooks, err := NewHooks(
+		WithTracerProvider(tp),
+		WithMeterProvider(mp),
+		WithAttributes(attribute.String("db.namespace", "test")))
+	require.NoError(t, err)
+
+	c, err := duckdb.NewConnectorWithConfig(``, duckdb.Config{}, duckdb.WithHooks(hooks))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	t.Cleanup(func() {
+		require.NoError(t, db.Close())
+	})
+	return db, spans, reader
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			metrics[m.Name] = m.Data
+		}
+	}
+	return metrics
+}
+
+func sum(t *testing.T, data metricdata.Aggregation) int64 {
+	s, ok := data.(metricdata.Sum[int64])
+	require.True(t, ok)
+
+	var total int64
+	for _, dp := range s.DataPoints {
+		total += dp.Value
+	}
+	return total
+}
+
+func TestStatementSpans(t *testing.T) {
+	db, spans, reader := setup(t)
+
+	tp := sdktrace.NewTracerProvider()
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	_, err := db.ExecContext(ctx, `CREATE TABLE t (i INTEGER)`)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, `INSERT INTO t SELECT range FROM range(3000)`)
+	require.NoError(t, err)
+
+	r, err := db.QueryContext(ctx, `SELECT i FROM t`)
+	require.NoError(t, err)
+	for r.Next() {
+	}
+	require.NoError(t, r.Close())
+
+	_, err = db.ExecContext(ctx, `INSERT INTO t VALUES (?)`, "not a number")
+	require.Error(t, err)
+	parent.End()
+
+	ended := spans.Ended()
+	require.Len(t, ended, 5)
+
+	create, insert, sel, rows, failed := ended[0], ended[1], ended[2], ended[3], ended[4]
+	require.Equal(t, "CREATE", create.Name())
+	require.Equal(t, trace.SpanKindClient, create.SpanKind())
+	require.Equal(t, parent.SpanContext().SpanID(), create.Parent().SpanID())
+	require.Equal(t, "duckdb", spanAttr(create, dbSystemNameKey).AsString())
+	require.Equal(t, "test", spanAttr(create, "db.namespace").AsString())
+	require.Equal(t, `CREATE TABLE t (i INTEGER)`, spanAttr(create, dbQueryTextKey).AsString())
+
+	require.Equal(t, "INSERT", insert.Name())
+	require.Equal(t, int64(3000), spanAttr(insert, rowsChangedKey).AsInt64())
+
+	require.Equal(t, "SELECT", sel.Name())
+	require.Equal(t, rowsSpanName, rows.Name())
+	require.Equal(t, sel.SpanContext().SpanID(), rows.Parent().SpanID())
+	require.Equal(t, int64(3000), spanAttr(rows, rowsKey).AsInt64())
+	require.Equal(t, int64(2), spanAttr(rows, chunksKey).AsInt64())
+
+	require.Equal(t, codes.Error, failed.Status().Code)
+	require.Equal(t, duckdb.ErrorTypeConversion.String(), spanAttr(failed, errorTypeKey).AsString())
+
+	metrics := collect(t, reader)
+	require.Equal(t, int64(3000), sum(t, metrics[rowsChangedName]))
+	require.Equal(t, int64(3000), sum(t, metrics[rowsScannedName]))
+	require.Equal(t, int64(2), sum(t, metrics[chunksName]))
+
+	durations, ok := metrics[operationDurationName].(metricdata.Histogram[float64])
+	require.True(t, ok)
+	var count uint64
+	var errCount uint64
+	for _, dp := range durations.DataPoints {
+		count += dp.Count
+		if v, ok := dp.Attributes.Value(errorTypeKey); ok {
+			require.Equal(t, "Conversion", v.AsString())
+			errCount += dp.Count
+		}
+	}
+	require.Equal(t, uint64(4), count)
+	require.Equal(t, uint64(1), errCount)
+}
+
+func TestWithoutQueryText(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	hooks, err := NewHooks(WithTracerProvider(tp), WithoutQueryText())
+	require.NoError(t, err)
+
+	c, err := duckdb.NewConnectorWithConfig(``, duckdb.Config{}, duckdb.WithHooks(hooks))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	_, err = db.Exec(`SELECT 'secret'`)
+	require.NoError(t, err)
+
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	require.Equal(t, attribute.INVALID, spanAttr(ended[0], dbQueryTextKey).Type())
+}
+
+type rejectHooks struct {
+	duckdb.NoopHooks
+}
+
+func (rejectHooks) BeforeExecute(ctx context.Context, _ *duckdb.StmtInfo) (context.Context, error) {
+	return ctx, errors.New("rejected")
+}
+
+func TestCallerSpanUnchanged(t *testing.T) {
+	spans := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans))
+	hooks, err := NewHooks(WithTracerProvider(tp))
+	require.NoError(t, err)
+
+	c, err := duckdb.NewConnectorWithConfig(``, duckdb.Config{}, duckdb.WithHooks(rejectHooks{}, hooks))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+	_, err = db.ExecContext(ctx, `SELECT 42`)
+	require.ErrorContains(t, err, "rejected")
+
+	// AfterExecute does not end the caller's span, even if it is called without BeforeExecute.
+	hooks.AfterExecute(ctx, duckdb.StmtInfo{Type: duckdb.STATEMENT_TYPE_SELECT}, duckdb.StmtResult{Err: errors.New("failed")})
+	require.Empty(t, spans.Ended())
+
+	parent.End()
+	ended := spans.Ended()
+	require.Len(t, ended, 1)
+	require.Equal(t, "parent", ended[0].Name())
+	require.Equal(t, codes.Unset, ended[0].Status().Code)
+}
+
+type double struct{}
+
+func (double) Config() duckdb.ScalarFuncConfig {
+	info, _ := duckdb.NewTypeInfo(duckdb.TYPE_BIGINT)
+	return duckdb.ScalarFuncConfig{
+		InputTypeInfos: []duckdb.TypeInfo{info},
+		ResultTypeInfo: info,
+	}
+}
+
+func (double) Executor() duckdb.ScalarFuncExecutor {
+	return duckdb.ScalarFuncExecutor{RowExecutor: func(values []driver.Value) (any, error) {
+		return 2 * values[0].(int64), nil
+	}}
+}
+
+func TestUDFAndAppenderMetrics(t *testing.T) {
+	db, _, reader := setup(t)
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, conn.Close())
+	}()
+
+	require.NoError(t, duckdb.RegisterScalarUDF(conn, "double", &double{}))
+	var total int64
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT sum(double(range))::BIGINT FROM range(10)`).Scan(&total))
+	require.Equal(t, int64(90), total)
+
+	_, err = conn.ExecContext(context.Background(), `CREATE TABLE t (i BIGINT)`)
+	require.NoError(t, err)
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		a, err := duckdb.NewAppenderFromConn(driverConn.(driver.Conn), "", "t")
+		require.NoError(t, err)
+		for i := range 5 {
+			require.NoError(t, a.AppendRow(int64(i)))
+		}
+		return a.Close()
+	}))
+
+	metrics := collect(t, reader)
+	require.Equal(t, int64(10), sum(t, metrics[udfRowsName]))
+	require.Equal(t, int64(5), sum(t, metrics[appenderRowsName]))
+
+	udfRows, ok := metrics[udfRowsName].(metricdata.Sum[int64])
+	require.True(t, ok)
+	name, ok := udfRows.DataPoints[0].Attributes.Value(udfNameKey)
+	require.True(t, ok)
+	require.Equal(t, "double", name.AsString())
+}
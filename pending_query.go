@@ -0,0 +1,246 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// PendingQuery is a query executing asynchronously on a connection.
+// Drive its execution with Poll, or wait for it with Done, then obtain its rows with Rows, or discard them with Close.
+// The connection must not execute other statements until the rows of the PendingQuery are closed,
+// or until the PendingQuery is closed.
+type PendingQuery struct {
+	stmt     *Stmt
+	stmtType StmtType
+	// The context of the query, which contains the values added by the hooks.
+	ctx       context.Context
+	info      StmtInfo
+	startTime time.Time
+	// The number of hooks whose BeforeExecute hook was called.
+	hooksCalled int
+
+	stopCancel func() bool
+	cleanupCtx func()
+
+	// mu protects the pending result and the result of the query.
+	mu         sync.Mutex
+	pendingRes mapping.PendingResult
+	finished   bool
+	res        *mapping.Result
+	err        error
+	// True, if Rows or Close consumed the result.
+	consumed bool
+
+	// cancelMu serializes interrupting the query with finishing it,
+	// so that Cancel never interrupts a later statement of the connection.
+	cancelMu  sync.Mutex
+	completed bool
+
+	waitOnce sync.Once
+	waiting  atomic.Bool
+	done     chan struct{}
+}
+
+// StartQuery starts executing a query that may return rows, such as a SELECT, and returns without waiting for it.
+// If the query contains multiple statements, then StartQuery executes all but the last statement before returning.
+// If ctx is done before the query finishes, the query is canceled.
+// Connector.Shutdown only waits for the query while Poll or Done execute it.
+//
+// database/sql serializes the use of a connection, so obtain a dedicated *Conn with Connector.Connect.
+func (conn *Conn) StartQuery(ctx context.Context, query string, args []driver.NamedValue) (*PendingQuery, error) {
+	s, err := conn.prepareStmts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.startPending(ctx, args)
+	if err != nil {
+		return nil, errors.Join(err, s.Close())
+	}
+	return p, nil
+}
+
+func (s *Stmt) startPending(ctx context.Context, args []driver.NamedValue) (*PendingQuery, error) {
+	if err := s.bind(args); err != nil {
+		return nil, err
+	}
+
+	p := &PendingQuery{
+		stmt:      s,
+		stmtType:  StmtType(mapping.PreparedStatementType(*s.preparedStmt)),
+		ctx:       ctx,
+		info:      s.stmtInfo(args),
+		startTime: time.Now(),
+		done:      make(chan struct{}),
+	}
+
+	var err error
+	if p.ctx, p.hooksCalled, err = s.conn.connector.beforeExecute(ctx, &p.info); err == nil {
+		err = p.begin()
+	}
+	if err != nil {
+		p.afterExecute(nil, err)
+		return nil, err
+	}
+	return p, nil
+}
+
+// begin creates the pending result of the query.
+// The query only counts as an executing statement of the connector while creating the pending result,
+// and while Poll or finish execute it, so that an undriven query does not block Connector.Shutdown.
+func (p *PendingQuery) begin() error {
+	s := p.stmt
+	if err := s.conn.connector.beginStmt(false); err != nil {
+		return err
+	}
+	defer s.conn.connector.endStmt()
+
+	if mapping.PendingPrepared(*s.preparedStmt, &p.pendingRes) == mapping.StateError {
+		dbErr := getDuckDBError(mapping.PendingError(p.pendingRes))
+		mapping.DestroyPending(&p.pendingRes)
+		s.conn.recordStmt(p.stmtType, dbErr)
+		return dbErr
+	}
+
+	// Expose the context to user-defined functions.
+	p.cleanupCtx = s.conn.setContext(p.ctx)
+	p.stopCancel = context.AfterFunc(p.ctx, p.Cancel)
+	return nil
+}
+
+// Poll executes a task of the query, and returns true, if the query finished.
+// If Done started waiting for the query, Poll only reports whether the query finished.
+// Once the query finished, Poll returns the error of the query, if any.
+func (p *PendingQuery) Poll() (bool, error) {
+	if p.waiting.Load() {
+		select {
+		case <-p.done:
+			return true, p.err
+		default:
+			return false, nil
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.finished {
+		return true, p.err
+	}
+
+	p.stmt.conn.connector.resumeStmt()
+	state := mapping.PendingExecuteTask(p.pendingRes)
+	p.stmt.conn.connector.endStmt()
+	if !mapping.PendingExecutionIsFinished(state) {
+		return false, nil
+	}
+	p.finish()
+	return true, p.err
+}
+
+// Done starts executing the remaining tasks of the query on a separate goroutine,
+// and returns a channel that is closed when the query finishes.
+func (p *PendingQuery) Done() <-chan struct{} {
+	p.waitOnce.Do(func() {
+		p.waiting.Store(true)
+		go func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			if !p.finished {
+				p.finish()
+			}
+		}()
+	})
+	return p.done
+}
+
+// Cancel interrupts the query, if it did not finish yet.
+// The query then finishes with an error.
+func (p *PendingQuery) Cancel() {
+	p.cancelMu.Lock()
+	defer p.cancelMu.Unlock()
+	if !p.completed {
+		mapping.Interrupt(p.stmt.conn.conn)
+	}
+}
+
+// Rows waits for the query to finish, and returns its rows, or its error.
+// Rows can only be called once.
+func (p *PendingQuery) Rows() (driver.Rows, error) {
+	<-p.Done()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.consumed {
+		return nil, errPendingQueryConsumed
+	}
+	p.consumed = true
+
+	if p.err != nil {
+		return nil, errors.Join(p.err, p.stmt.Close())
+	}
+	return newRowsOwningStmt(*p.res, p.stmt), nil
+}
+
+// Close cancels the query, if it did not finish yet, and discards its result.
+// It is a no-op after Rows.
+func (p *PendingQuery) Close() error {
+	p.Cancel()
+	<-p.Done()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.consumed {
+		return nil
+	}
+	p.consumed = true
+
+	if p.res != nil {
+		mapping.DestroyResult(p.res)
+	}
+	return p.stmt.Close()
+}
+
+// finish executes the remaining tasks of the query, and records its result.
+// The caller must hold mu.
+func (p *PendingQuery) finish() {
+	p.stmt.conn.connector.resumeStmt()
+	var res mapping.Result
+	state := mapping.ExecutePending(p.pendingRes, &res)
+	mapping.DestroyPending(&p.pendingRes)
+	p.stmt.conn.connector.endStmt()
+
+	p.cancelMu.Lock()
+	p.completed = true
+	p.cancelMu.Unlock()
+	p.stopCancel()
+	p.cleanupCtx()
+
+	p.res, p.err = p.stmt.pendingResult(p.ctx, p.stmtType, state, &res)
+	p.afterExecute(p.res, p.err)
+
+	p.finished = true
+	close(p.done)
+}
+
+// afterExecute calls the connector's hooks after executing the query,
+// and keeps the context of the hooks for the hooks of the rows.
+func (p *PendingQuery) afterExecute(res *mapping.Result, err error) {
+	if p.hooksCalled == 0 {
+		return
+	}
+
+	stmtRes := StmtResult{Duration: time.Since(p.startTime), Err: err}
+	if res != nil {
+		stmtRes.RowsChanged = int64(mapping.RowsChanged(res))
+	}
+	p.stmt.conn.connector.afterExecute(p.ctx, p.hooksCalled, p.info, stmtRes)
+
+	p.stmt.hookCtx = p.ctx
+	p.stmt.hookInfo = p.info
+}
@@ -0,0 +1,118 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPendingQuery(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t AS SELECT i FROM range(1000000) r(i)`)
+
+	driverConn, err := c.Connect(context.Background())
+	require.NoError(t, err)
+	conn := driverConn.(*Conn)
+	defer closeDriverConnWrapper(t, &driverConn)
+
+	sum := func(rows driver.Rows) int64 {
+		defer func() {
+			require.NoError(t, rows.Close())
+		}()
+		values := make([]driver.Value, 1)
+		require.NoError(t, rows.Next(values))
+		require.ErrorIs(t, rows.Next(values), io.EOF)
+		return values[0].(*big.Int).Int64()
+	}
+
+	t.Run("poll", func(t *testing.T) {
+		p, err := conn.StartQuery(context.Background(), `SELECT sum(i) FROM t WHERE i < ?`, []driver.NamedValue{{Ordinal: 1, Value: int64(10)}})
+		require.NoError(t, err)
+
+		done := false
+		for !done {
+			done, err = p.Poll()
+			require.NoError(t, err)
+		}
+		rows, err := p.Rows()
+		require.NoError(t, err)
+		require.Equal(t, int64(45), sum(rows))
+
+		_, err = p.Rows()
+		require.ErrorIs(t, err, errPendingQueryConsumed)
+		require.NoError(t, p.Close())
+	})
+
+	t.Run("done", func(t *testing.T) {
+		p, err := conn.StartQuery(context.Background(), `SELECT sum(i) FROM t`, nil)
+		require.NoError(t, err)
+		<-p.Done()
+		done, err := p.Poll()
+		require.True(t, done)
+		require.NoError(t, err)
+
+		rows, err := p.Rows()
+		require.NoError(t, err)
+		require.Equal(t, int64(499999500000), sum(rows))
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p, err := conn.StartQuery(ctx, `SELECT count(*) FROM range(10000000000) r(i) WHERE i % 7 = 3`, nil)
+		require.NoError(t, err)
+		cancel()
+
+		_, err = p.Rows()
+		require.ErrorIs(t, err, context.Canceled)
+
+		// The connection remains usable.
+		p, err = conn.StartQuery(context.Background(), `SELECT sum(i) FROM t WHERE i < 10`, nil)
+		require.NoError(t, err)
+		p.Cancel()
+		_, err = p.Rows()
+		var duckdbErr *Error
+		require.True(t, errors.As(err, &duckdbErr))
+		require.Equal(t, ErrorTypeInterrupt, duckdbErr.Type)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		p, err := conn.StartQuery(context.Background(), `SELECT i FROM t`, nil)
+		require.NoError(t, err)
+		require.NoError(t, p.Close())
+		require.NoError(t, p.Close())
+
+		_, err = conn.StartQuery(context.Background(), `SELECT * FROM missing`, nil)
+		require.ErrorContains(t, err, "missing")
+	})
+}
+
+func TestPendingQueryShutdown(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	driverConn, err := c.Connect(context.Background())
+	require.NoError(t, err)
+	conn := driverConn.(*Conn)
+	defer closeDriverConnWrapper(t, &driverConn)
+
+	// An undriven query does not block the shutdown.
+	p, err := conn.StartQuery(context.Background(),
+		`SELECT count(*) FROM range(1_000_000_000) t(i) WHERE i % 7 = 0`, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	require.NoError(t, c.Shutdown(ctx))
+
+	// The connection cannot start new queries, but can still discard the started query.
+	_, err = conn.StartQuery(context.Background(), `SELECT 42`, nil)
+	require.ErrorIs(t, err, errShutdown)
+	require.NoError(t, p.Close())
+}
@@ -23,19 +23,26 @@ func GetProfilingInfo(c *sql.Conn) (ProfilingInfo, error) {
 	info := ProfilingInfo{}
 	err := c.Raw(func(driverConn any) error {
 		conn := driverConn.(*Conn)
-		profilingInfo := mapping.GetProfilingInfo(conn.conn)
-		if profilingInfo.Ptr == nil {
-			return getError(errProfilingInfoEmpty, nil)
-		}
-
-		// Recursive tree traversal.
-		info.getMetrics(profilingInfo)
-		return nil
+		var err error
+		info, err = conn.profilingInfo()
+		return err
 	})
 
 	return info, err
 }
 
+func (conn *Conn) profilingInfo() (ProfilingInfo, error) {
+	info := ProfilingInfo{}
+	profilingInfo := mapping.GetProfilingInfo(conn.conn)
+	if profilingInfo.Ptr == nil {
+		return info, getError(errProfilingInfoEmpty, nil)
+	}
+
+	// Recursive tree traversal.
+	info.getMetrics(profilingInfo)
+	return info, nil
+}
+
 func (info *ProfilingInfo) getMetrics(profilingInfo mapping.ProfilingInfo) {
 	metricsMap := mapping.ProfilingInfoGetMetrics(profilingInfo)
 	count := mapping.GetMapSize(metricsMap)
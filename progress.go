@@ -0,0 +1,77 @@
+package duckdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// progressInterval is the interval between two calls of a ProgressFunc.
+const progressInterval = 100 * time.Millisecond
+
+// Progress is the progress of the query currently executing on a connection.
+type Progress struct {
+	// Percentage is the estimated percentage of the query's work that is done.
+	// It is -1, if no progress information is available.
+	Percentage float64
+	// RowsProcessed is the number of rows processed so far.
+	RowsProcessed uint64
+	// TotalRows is the estimated total number of rows to process.
+	TotalRows uint64
+}
+
+// ProgressFunc receives the progress of a running query.
+type ProgressFunc func(percent float64, rowsProcessed, totalRows uint64)
+
+type progressKey struct{}
+
+// WithProgress returns a context with a progress callback.
+// Statements executed with the context call fn periodically while they run.
+// fn runs on a separate goroutine, and must not use the connection executing the statement.
+//
+// DuckDB only tracks the progress of queries with the progress bar enabled,
+// so the connection enables the progress bar, without printing it, before executing the statement.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// QueryProgress returns the progress of the query currently executing on the connection with the id connId.
+// database/sql serializes the calls on a *sql.Conn, so use ConnId to obtain the id before executing the query,
+// and call QueryProgress from another goroutine.
+// The connection must enable the progress bar, e.g., by executing the query with WithProgress,
+// or by executing SET enable_progress_bar = true.
+func (c *Connector) QueryProgress(connId uint64) (Progress, error) {
+	conn := c.connById(connId)
+	if conn == nil {
+		return Progress{}, getError(errAPI, errConnNotFound)
+	}
+	return conn.queryProgress(), nil
+}
+
+func (conn *Conn) queryProgress() Progress {
+	progress := mapping.QueryProgress(conn.conn)
+	percentage, rowsProcessed, totalRows := mapping.QueryProgressTypeMembers(&progress)
+	return Progress{Percentage: percentage, RowsProcessed: rowsProcessed, TotalRows: totalRows}
+}
+
+// enableProgress enables the progress bar of the connection, without printing it.
+// Resetting the session restores the settings.
+func (conn *Conn) enableProgress() error {
+	if conn.progress {
+		return nil
+	}
+	for _, query := range []string{`SET enable_progress_bar = true`, `SET enable_progress_bar_print = false`} {
+		if _, err := conn.queryInternal(query); err != nil {
+			return err
+		}
+	}
+	conn.progress = true
+	conn.settingsDirty = true
+	return nil
+}
@@ -0,0 +1,62 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgress(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t AS SELECT i, i::VARCHAR AS s FROM range(5000000) r(i)`)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+	connId, err := ConnId(conn)
+	require.NoError(t, err)
+
+	var mu sync.Mutex
+	var calls int
+	var last Progress
+	ctx := WithProgress(context.Background(), func(percent float64, rowsProcessed, totalRows uint64) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		last = Progress{Percentage: percent, RowsProcessed: rowsProcessed, TotalRows: totalRows}
+	})
+
+	// The callback runs while the query executes.
+	called := func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return calls != 0
+	}
+	var count int
+	for !called() {
+		err = conn.QueryRowContext(ctx, `SELECT count(*) FROM (SELECT s, count(*) FROM t GROUP BY s ORDER BY s)`).Scan(&count)
+		require.NoError(t, err)
+		require.Equal(t, 5000000, count)
+	}
+	require.LessOrEqual(t, last.Percentage, 100.0)
+	require.LessOrEqual(t, last.RowsProcessed, last.TotalRows)
+
+	// The progress bar is enabled, but not printed.
+	var enabled, printed bool
+	require.NoError(t, conn.QueryRowContext(context.Background(),
+		`SELECT current_setting('enable_progress_bar'), current_setting('enable_progress_bar_print')`).Scan(&enabled, &printed))
+	require.True(t, enabled)
+	require.False(t, printed)
+
+	// No query is executing.
+	progress, err := c.QueryProgress(connId)
+	require.NoError(t, err)
+	require.Equal(t, -1.0, progress.Percentage)
+
+	_, err = c.QueryProgress(connId + 1000)
+	testError(t, err, errAPI.Error(), errConnNotFound.Error())
+}
@@ -0,0 +1,116 @@
+// Package promduckdb exports the statistics of a duckdb.Connector as Prometheus metrics.
+//
+//	c, err := duckdb.NewConnector("my.db", nil)
+//	...
+//	prometheus.MustRegister(promduckdb.NewCollector(c, prometheus.Labels{"db": "my"}))
+package promduckdb
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/marcboeker/go-duckdb/v2"
+)
+
+const namespace = "duckdb"
+
+const (
+	udfKindScalar = "scalar"
+	udfKindTable  = "table"
+)
+
+// Collector implements prometheus.Collector for the statistics of a duckdb.Connector.
+type Collector struct {
+	c *duckdb.Connector
+
+	openConnections *prometheus.Desc
+	activeQueries   *prometheus.Desc
+	statements      *prometheus.Desc
+	errors          *prometheus.Desc
+	appenderFlushes *prometheus.Desc
+	appenderRows    *prometheus.Desc
+	stmtCacheHits   *prometheus.Desc
+	stmtCacheMisses *prometheus.Desc
+	udfCalls        *prometheus.Desc
+	udfRows         *prometheus.Desc
+	udfErrors       *prometheus.Desc
+	udfDuration     *prometheus.Desc
+}
+
+var _ prometheus.Collector = (*Collector)(nil)
+
+// NewCollector returns a Collector for the statistics of c.
+// constLabels are added to all metrics, e.g., to distinguish multiple connectors.
+func NewCollector(c *duckdb.Connector, constLabels prometheus.Labels) *Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", name), help, labels, constLabels)
+	}
+
+	return &Collector{
+		c:               c,
+		openConnections: desc("open_connections", "Number of open connections."),
+		activeQueries:   desc("active_queries", "Number of currently executing statements."),
+		statements:      desc("statements_total", "Number of executed statements.", "type"),
+		errors:          desc("errors_total", "Number of DuckDB errors.", "type"),
+		appenderFlushes: desc("appender_flushes_total", "Number of appender flushes."),
+		appenderRows:    desc("appender_rows_total", "Number of flushed appender rows."),
+		stmtCacheHits:   desc("stmt_cache_hits_total", "Number of queries reusing a cached prepared statement."),
+		stmtCacheMisses: desc("stmt_cache_misses_total", "Number of queries missing the prepared statement cache."),
+		udfCalls:        desc("udf_calls_total", "Number of executions of user-defined functions on data chunks.", "name", "kind"),
+		udfRows:         desc("udf_rows_total", "Number of rows processed by user-defined functions.", "name", "kind"),
+		udfErrors:       desc("udf_errors_total", "Number of failed executions of user-defined functions.", "name", "kind"),
+		udfDuration:     desc("udf_duration_seconds", "Execution time of user-defined functions on data chunks.", "name", "kind"),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openConnections
+	ch <- c.activeQueries
+	ch <- c.statements
+	ch <- c.errors
+	ch <- c.appenderFlushes
+	ch <- c.appenderRows
+	ch <- c.stmtCacheHits
+	ch <- c.stmtCacheMisses
+	ch <- c.udfCalls
+	ch <- c.udfRows
+	ch <- c.udfErrors
+	ch <- c.udfDuration
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.c.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.openConnections, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.activeQueries, prometheus.GaugeValue, float64(stats.ActiveQueries))
+	for t, count := range stats.Statements {
+		ch <- prometheus.MustNewConstMetric(c.statements, prometheus.CounterValue, float64(count), t.String())
+	}
+	for t, count := range stats.Errors {
+		ch <- prometheus.MustNewConstMetric(c.errors, prometheus.CounterValue, float64(count), t.String())
+	}
+	ch <- prometheus.MustNewConstMetric(c.appenderFlushes, prometheus.CounterValue, float64(stats.AppenderFlushes))
+	ch <- prometheus.MustNewConstMetric(c.appenderRows, prometheus.CounterValue, float64(stats.AppenderRows))
+	ch <- prometheus.MustNewConstMetric(c.stmtCacheHits, prometheus.CounterValue, float64(stats.StmtCacheHits))
+	ch <- prometheus.MustNewConstMetric(c.stmtCacheMisses, prometheus.CounterValue, float64(stats.StmtCacheMisses))
+
+	for name, udf := range stats.UDFs {
+		kind := udfKindScalar
+		if udf.Table {
+			kind = udfKindTable
+		}
+		ch <- prometheus.MustNewConstMetric(c.udfCalls, prometheus.CounterValue, float64(udf.Calls), name, kind)
+		ch <- prometheus.MustNewConstMetric(c.udfRows, prometheus.CounterValue, float64(udf.Rows), name, kind)
+		ch <- prometheus.MustNewConstMetric(c.udfErrors, prometheus.CounterValue, float64(udf.Errors), name, kind)
+
+		// Prometheus expects cumulative bucket counts.
+		buckets := make(map[float64]uint64, len(udf.Latency.Bounds))
+		var cumulative uint64
+		for i, bound := range udf.Latency.Bounds {
+			cumulative += udf.Latency.Counts[i]
+			buckets[bound.Seconds()] = cumulative
+		}
+		ch <- prometheus.MustNewConstHistogram(c.udfDuration, udf.Latency.Count, udf.Latency.Sum.Seconds(), buckets, name, kind)
+	}
+}
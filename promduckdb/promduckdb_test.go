@@ -0,0 +1,52 @@
+package promduckdb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/marcboeker/go-duckdb/v2"
+)
+
+func TestCollector(t *testing.T) {
+	c, err := duckdb.NewConnector(``, nil)
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer func() {
+		require.NoError(t, db.Close())
+	}()
+
+	conn, err := db.Conn(context.Background())
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, conn.Close())
+	}()
+
+	_, err = conn.ExecContext(context.Background(), `CREATE TABLE t (i INTEGER)`)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(context.Background(), `SELECT * FROM does_not_exist`)
+	require.Error(t, err)
+
+	collector := NewCollector(c, prometheus.Labels{"db": "test"})
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	expected := `
+# HELP duckdb_open_connections Number of open connections.
+# TYPE duckdb_open_connections gauge
+duckdb_open_connections{db="test"} 1
+# HELP duckdb_statements_total Number of executed statements.
+# TYPE duckdb_statements_total counter
+duckdb_statements_total{db="test",type="CREATE"} 1
+# HELP duckdb_errors_total Number of DuckDB errors.
+# TYPE duckdb_errors_total counter
+duckdb_errors_total{db="test",type="Catalog"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"duckdb_open_connections", "duckdb_statements_total", "duckdb_errors_total"))
+}
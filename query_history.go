@@ -0,0 +1,346 @@
+package duckdb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// QueryHistoryOptions configures the query history of a Connector.
+type QueryHistoryOptions struct {
+	// Size is the number of recently executed statements kept in the history.
+	// If zero, the Connector keeps no history, but still logs slow statements.
+	Size int
+	// SlowThreshold is the minimum duration of a statement to log it as slow.
+	// If zero, the Connector does not log slow statements.
+	SlowThreshold time.Duration
+	// Logger logs slow statements. It defaults to slog.Default().
+	Logger *slog.Logger
+	// Profiling enables profiling on all connections of the Connector,
+	// and attaches the profiling information to slow statements.
+	Profiling bool
+	// RawQueries keeps and logs the query text of statements as they are,
+	// including literal values, e.g., passwords or personal data.
+	// By default, the Connector only keeps and logs the fingerprints of statements.
+	RawQueries bool
+}
+
+// QueryRecord describes an executed statement in the query history.
+type QueryRecord struct {
+	// Start is the start time of the execution.
+	Start time.Time
+	// Query is the query of the statement, if RawQueries is set. Otherwise, it is the fingerprint.
+	Query string
+	// Fingerprint is the normalized query, with all literals replaced by '?'.
+	// Statements differing only in their literals have the same fingerprint.
+	Fingerprint string
+	// Args contains the types of the arguments. Their values are redacted.
+	Args []string
+	// Type is the type of the statement.
+	Type StmtType
+	// Duration is the execution time of the statement.
+	Duration time.Duration
+	// RowsChanged is the number of rows changed by the statement.
+	RowsChanged int64
+	// ConnId is the id of the connection executing the statement.
+	ConnId uint64
+	// Err is the error of the statement, if any.
+	Err error
+	// Profile contains the profiling information of slow statements, if profiling is enabled.
+	Profile *ProfilingInfo
+}
+
+// WithQueryHistory keeps a bounded history of recently executed statements and logs slow statements.
+// Use Connector.QueryHistory to inspect the history.
+func WithQueryHistory(opts QueryHistoryOptions) ConnectorOption {
+	return func(connectorOpts *connectorOptions) {
+		connectorOpts.history = &opts
+	}
+}
+
+// QueryHistory returns the recently executed statements of the Connector, from oldest to newest.
+// It returns nil, if the Connector does not keep a query history.
+func (c *Connector) QueryHistory() []QueryRecord {
+	if c.history == nil {
+		return nil
+	}
+	return c.history.records()
+}
+
+// queryHistory implements Hooks to record executed statements.
+type queryHistory struct {
+	NoopHooks
+	opts      QueryHistoryOptions
+	connector *Connector
+
+	mu sync.Mutex
+	// ring contains the records. next is the index of the next record to overwrite.
+	ring []QueryRecord
+	next int
+	full bool
+}
+
+func newQueryHistory(opts QueryHistoryOptions, c *Connector) *queryHistory {
+	if opts.Logger == nil {
+		opts.Logger = slog.Default()
+	}
+	return &queryHistory{
+		opts:      opts,
+		connector: c,
+		ring:      make([]QueryRecord, max(opts.Size, 0)),
+	}
+}
+
+func (h *queryHistory) AfterExecute(ctx context.Context, info StmtInfo, res StmtResult) {
+	record := QueryRecord{
+		Start:       time.Now().Add(-res.Duration),
+		Fingerprint: fingerprint(info.Query),
+		Args:        redactArgs(info),
+		Type:        info.Type,
+		Duration:    res.Duration,
+		RowsChanged: res.RowsChanged,
+		ConnId:      info.ConnId,
+		Err:         res.Err,
+	}
+	record.Query = record.Fingerprint
+	if h.opts.RawQueries {
+		record.Query = info.Query
+	}
+
+	if h.opts.SlowThreshold > 0 && res.Duration >= h.opts.SlowThreshold {
+		if h.opts.Profiling {
+			record.Profile = h.profile(info.ConnId)
+			if record.Profile != nil && !h.opts.RawQueries {
+				redactProfile(record.Profile, record.Fingerprint)
+			}
+		}
+		h.logSlow(ctx, record)
+	}
+
+	h.add(record)
+}
+
+func (h *queryHistory) add(record QueryRecord) {
+	if len(h.ring) == 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.ring[h.next] = record
+	h.next = (h.next + 1) % len(h.ring)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *queryHistory) records() []QueryRecord {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if !h.full {
+		return append([]QueryRecord(nil), h.ring[:h.next]...)
+	}
+	records := make([]QueryRecord, 0, len(h.ring))
+	records = append(records, h.ring[h.next:]...)
+	return append(records, h.ring[:h.next]...)
+}
+
+func (h *queryHistory) logSlow(ctx context.Context, record QueryRecord) {
+	attrs := []slog.Attr{
+		slog.String("fingerprint", record.Fingerprint),
+		slog.Any("args", record.Args),
+		slog.String("type", record.Type.String()),
+		slog.Duration("duration", record.Duration),
+		slog.Int64("rows_changed", record.RowsChanged),
+		slog.Uint64("conn_id", record.ConnId),
+	}
+	if h.opts.RawQueries {
+		attrs = append(attrs, slog.String("query", record.Query))
+	}
+	if record.Err != nil {
+		attrs = append(attrs, slog.String("error", record.Err.Error()))
+	}
+	if record.Profile != nil {
+		attrs = append(attrs, slog.Any("profile", *record.Profile))
+	}
+	h.opts.Logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+}
+
+// profile returns the profiling information of the last statement of a connection.
+// The connection is executing the hook, so it is safe to access.
+func (h *queryHistory) profile(connId uint64) *ProfilingInfo {
+	conn := h.connector.connById(connId)
+	if conn == nil {
+		return nil
+	}
+	info, err := conn.profilingInfo()
+	if err != nil {
+		return nil
+	}
+	return &info
+}
+
+// enableProfiling enables profiling on a new connection, if the query history attaches profiling information.
+func (h *queryHistory) enableProfiling(conn *Conn) error {
+	if !h.opts.Profiling {
+		return nil
+	}
+
+	var res mapping.Result
+	defer mapping.DestroyResult(&res)
+	if mapping.Query(conn.conn, `PRAGMA enable_profiling = 'no_output'`, &res) == mapping.StateError {
+		return getDuckDBError(mapping.ResultError(&res))
+	}
+	return nil
+}
+
+// redactProfile replaces the query text of profiling information with its fingerprint,
+// and removes the operator details, as they might contain literal values.
+func redactProfile(info *ProfilingInfo, fingerprint string) {
+	if _, ok := info.Metrics["QUERY_NAME"]; ok {
+		info.Metrics["QUERY_NAME"] = fingerprint
+	}
+	delete(info.Metrics, "EXTRA_INFO")
+	for i := range info.Children {
+		redactProfile(&info.Children[i], fingerprint)
+	}
+}
+
+// redactArgs returns the types of the arguments of a statement.
+func redactArgs(info StmtInfo) []string {
+	if len(info.Args) == 0 {
+		return nil
+	}
+
+	args := make([]string, len(info.Args))
+	for i, arg := range info.Args {
+		args[i] = fmt.Sprintf("%T", arg.Value)
+		if arg.Name != "" {
+			args[i] = arg.Name + "=" + args[i]
+		}
+	}
+	return args
+}
+
+// fingerprint normalizes a query by lowercasing it, removing comments, collapsing whitespace,
+// and replacing string and numeric literals with '?'. Lists of literals collapse into a single '?'.
+func fingerprint(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	// lastRune returns the last written rune, or 0.
+	lastRune := func() rune {
+		s := b.String()
+		if s == "" {
+			return 0
+		}
+		return rune(s[len(s)-1])
+	}
+	space := false
+	write := func(s string) {
+		if space && b.Len() != 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+		b.WriteString(s)
+	}
+	isWord := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			space = true
+
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			space = true
+
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/') {
+				i++
+			}
+			i++
+			space = true
+
+		case r == '\'':
+			// Skip the string literal, including escaped quotes.
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			write("?")
+
+		case r == '"':
+			// Keep quoted identifiers as they are.
+			start := i
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+			}
+			write(string(runes[start:min(i+1, len(runes))]))
+
+		case unicode.IsDigit(r) && lastRune() != '$' && !(isWord(lastRune()) && !space):
+			for i+1 < len(runes) && (unicode.IsDigit(runes[i+1]) || runes[i+1] == '.' || runes[i+1] == 'e' || runes[i+1] == 'E' ||
+				((runes[i+1] == '+' || runes[i+1] == '-') && (runes[i] == 'e' || runes[i] == 'E'))) {
+				i++
+			}
+			write("?")
+
+		default:
+			write(string(unicode.ToLower(r)))
+		}
+	}
+
+	return collapseLists(b.String())
+}
+
+// collapseLists collapses comma-separated lists of '?' into a single '?'.
+func collapseLists(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for i := 0; i < len(s); i++ {
+		b.WriteByte(s[i])
+		if s[i] != '?' {
+			continue
+		}
+		// Skip any following ", ?" or ",?".
+		for {
+			j := i + 1
+			if j < len(s) && s[j] == ' ' {
+				j++
+			}
+			if j >= len(s) || s[j] != ',' {
+				break
+			}
+			j++
+			if j < len(s) && s[j] == ' ' {
+				j++
+			}
+			if j >= len(s) || s[j] != '?' {
+				break
+			}
+			i = j
+		}
+	}
+
+	return b.String()
+}
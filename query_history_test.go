@@ -0,0 +1,125 @@
+package duckdb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHistory(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithQueryHistory(QueryHistoryOptions{Size: 2}))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE t (i INTEGER, s VARCHAR)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO t VALUES (?, ?)`, 1, "secret")
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO t VALUES (?, ?)`, "not a number", "secret")
+	require.Error(t, err)
+
+	// The history keeps the last two statements.
+	history := c.QueryHistory()
+	require.Len(t, history, 2)
+
+	// By default, the history only keeps the fingerprints of statements.
+	require.Equal(t, `insert into t values (?)`, history[0].Query)
+	require.Equal(t, `insert into t values (?)`, history[0].Fingerprint)
+	require.Equal(t, []string{"int64", "string"}, history[0].Args)
+	require.Equal(t, STATEMENT_TYPE_INSERT, history[0].Type)
+	require.Equal(t, int64(1), history[0].RowsChanged)
+	require.NoError(t, history[0].Err)
+	require.Positive(t, history[0].Duration)
+
+	require.Error(t, history[1].Err)
+	require.Equal(t, history[0].ConnId, history[1].ConnId)
+	require.False(t, history[1].Start.Before(history[0].Start))
+
+	// Connectors without a query history.
+	c2, err := NewConnector(``, nil)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, c2.Close())
+	}()
+	require.Nil(t, c2.QueryHistory())
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	c, err := NewConnectorWithConfig(``, Config{}, WithQueryHistory(QueryHistoryOptions{
+		SlowThreshold: time.Nanosecond,
+		Logger:        logger,
+		Profiling:     true,
+	}))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	var count int
+	require.NoError(t, db.QueryRowContext(context.Background(), `SELECT count(*) FROM range(1000) WHERE range > ?`, 10).Scan(&count))
+	require.Equal(t, 989, count)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "slow query", entry["msg"])
+	require.Equal(t, "WARN", entry["level"])
+	require.Equal(t, `select count(*) from range(?) where range > ?`, entry["fingerprint"])
+	require.Equal(t, []any{"int64"}, entry["args"])
+	require.Equal(t, "SELECT", entry["type"])
+	require.NotEmpty(t, entry["profile"])
+	require.NotContains(t, entry, "query")
+	require.NotContains(t, buf.String(), "range(1000)")
+
+	// Without a size, the connector keeps no history.
+	require.Empty(t, c.QueryHistory())
+}
+
+func TestQueryHistoryRawQueries(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	c, err := NewConnectorWithConfig(``, Config{}, WithQueryHistory(QueryHistoryOptions{
+		Size:          1,
+		SlowThreshold: time.Nanosecond,
+		Logger:        logger,
+		RawQueries:    true,
+	}))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	_, err = db.Exec(`SELECT 'secret'`)
+	require.NoError(t, err)
+	require.Equal(t, `SELECT 'secret'`, c.QueryHistory()[0].Query)
+	require.Equal(t, `select ?`, c.QueryHistory()[0].Fingerprint)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, `SELECT 'secret'`, entry["query"])
+}
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{`SELECT 1`, `select ?`},
+		{"SELECT  *\n\tFROM t WHERE s = 'it''s' AND i IN (1, 2, 3)", `select * from t where s = ? and i in (?)`},
+		{`SELECT * FROM t1 WHERE x = 1.5e-3 -- comment`, `select * from t1 where x = ?`},
+		{`SELECT /* hint */ "MyCol" FROM t WHERE y = $1`, `select "MyCol" from t where y = $1`},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, fingerprint(tt.query), tt.query)
+	}
+}
@@ -32,6 +32,19 @@ func RegisterReplacementScan(c *Connector, callback ReplacementScanCallback) {
 	mapping.AddReplacementScan(c.db, callbackPtr, unsafe.Pointer(&h), deleteCallbackPtr)
 }
 
+// RegisterReplacementScan registers a replacement scan on the database of the Connector.
+// The replacement scan applies to all existing and future connections of the Connector.
+func (c *Connector) RegisterReplacementScan(callback ReplacementScanCallback) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return getError(errAPI, errClosedCon)
+	}
+
+	RegisterReplacementScan(c, callback)
+	return nil
+}
+
 //export replacement_scan_delete_callback
 func replacement_scan_delete_callback(info unsafe.Pointer) {
 	h := *(*cgo.Handle)(info)
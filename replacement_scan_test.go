@@ -36,3 +36,23 @@ func TestReplacementScan(t *testing.T) {
 	require.NoError(t, res.Err())
 	require.Equal(t, 0, rangeRows)
 }
+
+func TestConnectorReplacementScan(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	require.NoError(t, c.RegisterReplacementScan(func(tableName string) (string, []any, error) {
+		return "range", []any{int64(10)}, nil
+	}))
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM any_table`).Scan(&count))
+	require.Equal(t, 10, count)
+
+	closeConnectorWrapper(t, c)
+	err := c.RegisterReplacementScan(func(string) (string, []any, error) {
+		return "", nil, nil
+	})
+	testError(t, err, errAPI.Error(), errClosedCon.Error())
+}
@@ -0,0 +1,138 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// WithResultSets returns a result set per statement for queries with multiple statements.
+// Iterate over the result sets with database/sql's Rows.NextResultSet.
+// The connection executes each statement when advancing to its result set,
+// and the arguments of the query only apply to its last statement.
+// Closing the rows before advancing to the last result set skips the remaining statements.
+//
+// By default, the connection executes all but the last statement, discards their results,
+// and returns the result of the last statement.
+func WithResultSets() ConnectorOption {
+	return func(opts *connectorOptions) {
+		opts.resultSets = true
+	}
+}
+
+// resultSets contains the statements of a query returning a result set per statement.
+type resultSets struct {
+	ctx   context.Context
+	query string
+	args  []driver.NamedValue
+	stmts mapping.ExtractedStatements
+	count mapping.IdxT
+	// The index of the next statement to execute.
+	next mapping.IdxT
+}
+
+// queryResultSets executes the first statement of a query, and returns its rows.
+// The rows execute the remaining statements when advancing to their result sets.
+// Queries with a single statement use the prepared statement cache, if the Connector enables it.
+func (conn *Conn) queryResultSets(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if conn.closed {
+		return nil, errClosedCon
+	}
+
+	query, err := conn.connector.beforePrepare(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if conn.stmtCache != nil {
+		if s, ok := conn.cachedStmt(query); ok {
+			return conn.queryPrepared(ctx, s, true, args)
+		}
+	}
+
+	stmts, count, err := conn.extractStmts(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 1 {
+		defer mapping.DestroyExtracted(stmts)
+
+		cleanupCtx := conn.setContext(ctx)
+		s, err := conn.prepareExtractedStmt(*stmts, 0, query)
+		cleanupCtx()
+		if err != nil {
+			return nil, err
+		}
+		cached := conn.stmtCache != nil && conn.cacheStmt(query, s)
+		return conn.queryPrepared(ctx, s, cached, args)
+	}
+
+	sets := &resultSets{ctx: ctx, query: query, args: args, stmts: *stmts, count: count}
+	r, err := sets.execute(conn)
+	if err != nil {
+		mapping.DestroyExtracted(&sets.stmts)
+		return nil, err
+	}
+	return r, nil
+}
+
+// execute executes the next statement, and returns its rows.
+func (sets *resultSets) execute(conn *Conn) (*rows, error) {
+	cleanupCtx := conn.setContext(sets.ctx)
+	defer cleanupCtx()
+
+	i := sets.next
+	sets.next++
+
+	s, err := conn.prepareExtractedStmt(sets.stmts, i, sets.query)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []driver.NamedValue
+	if i == sets.count-1 {
+		args = sets.args
+	}
+	res, err := s.execute(sets.ctx, args)
+	if err != nil {
+		return nil, errors.Join(err, s.Close())
+	}
+
+	r := newRowsOwningStmt(*res, s)
+	r.sets = sets
+	return r, nil
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet.
+func (r *rows) HasNextResultSet() bool {
+	return r.sets != nil && r.sets.next < r.sets.count
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+// It closes the current result set, and executes the next statement.
+func (r *rows) NextResultSet() error {
+	if !r.HasNextResultSet() {
+		return io.EOF
+	}
+
+	if r.stmt == nil {
+		return errClosedStmt
+	}
+
+	conn := r.stmt.conn
+	sets := r.sets
+	if err := r.closeResult(); err != nil {
+		return err
+	}
+
+	next, err := sets.execute(conn)
+	if err != nil {
+		return err
+	}
+	*r = *next
+	return nil
+}
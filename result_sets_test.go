@@ -0,0 +1,86 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResultSets(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithResultSets())
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (i INTEGER, s VARCHAR)`)
+
+	r, err := db.QueryContext(context.Background(), `INSERT INTO t VALUES (1, 'a'), (2, 'b');
+		SELECT i FROM t ORDER BY i;
+		SELECT s FROM t WHERE i = ?`, 2)
+	require.NoError(t, err)
+	defer closeRowsWrapper(t, r)
+
+	// The result set of the INSERT.
+	var count int64
+	require.True(t, r.Next())
+	require.NoError(t, r.Scan(&count))
+	require.Equal(t, int64(2), count)
+
+	require.True(t, r.NextResultSet())
+	columns, err := r.Columns()
+	require.NoError(t, err)
+	require.Equal(t, []string{"i"}, columns)
+	var ids []int
+	for r.Next() {
+		var i int
+		require.NoError(t, r.Scan(&i))
+		ids = append(ids, i)
+	}
+	require.Equal(t, []int{1, 2}, ids)
+
+	// Arguments apply to the last statement.
+	require.True(t, r.NextResultSet())
+	var s string
+	require.True(t, r.Next())
+	require.NoError(t, r.Scan(&s))
+	require.Equal(t, "b", s)
+	require.False(t, r.Next())
+	require.False(t, r.NextResultSet())
+	require.NoError(t, r.Err())
+
+	// Statements execute when advancing to their result set.
+	r, err = db.QueryContext(context.Background(), `SELECT 1; SELECT * FROM missing; SELECT 3`)
+	require.NoError(t, err)
+	require.True(t, r.Next())
+	require.False(t, r.NextResultSet())
+	require.ErrorContains(t, r.Err(), "missing")
+	closeRowsWrapper(t, r)
+
+	// Closing the rows early releases the remaining statements.
+	r, err = db.QueryContext(context.Background(), `SELECT 1; SELECT 2`)
+	require.NoError(t, err)
+	closeRowsWrapper(t, r)
+
+	var i int
+	require.NoError(t, db.QueryRow(`SELECT 42`).Scan(&i))
+	require.Equal(t, 42, i)
+}
+
+func TestResultSetsStmtCache(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithResultSets(), WithStmtCache(StmtCacheOptions{Size: 2}))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	// Queries with a single statement use the prepared statement cache.
+	var i int
+	for range 2 {
+		require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT ?::INTEGER`, 7).Scan(&i))
+		require.Equal(t, 7, i)
+	}
+	require.Equal(t, uint64(1), c.Stats().StmtCacheHits)
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/marcboeker/go-duckdb/mapping"
 )
@@ -26,11 +27,25 @@ type rows struct {
 	chunkIdx mapping.IdxT
 	// rowCount is the number of scanned rows.
 	rowCount int
+	// totalRowCount is the number of scanned rows across all chunks.
+	totalRowCount int64
+	// sets contains the remaining statements, if the rows return a result set per statement.
+	sets *resultSets
+	// created is the creation time of the rows, if the connector has hooks.
+	created time.Time
 	// cached column metadata to avoid repeated CGO calls
 	scanTypes   []reflect.Type
 	dbTypeNames []string
 }
 
+// newRowsOwningStmt returns the rows of an internally prepared statement,
+// which closes after closing the rows.
+func newRowsOwningStmt(res mapping.Result, stmt *Stmt) *rows {
+	stmt.rows = true
+	stmt.closeOnRowsClose = true
+	return newRowsWithStmt(res, stmt)
+}
+
 func newRowsWithStmt(res mapping.Result, stmt *Stmt) *rows {
 	columnCount := mapping.ColumnCount(&res)
 	r := rows{
@@ -43,6 +58,9 @@ func newRowsWithStmt(res mapping.Result, stmt *Stmt) *rows {
 		scanTypes:   make([]reflect.Type, columnCount),
 		dbTypeNames: make([]string, columnCount),
 	}
+	if len(stmt.conn.connector.hooks) != 0 {
+		r.created = time.Now()
+	}
 
 	for i := mapping.IdxT(0); i < columnCount; i++ {
 		columnName := mapping.ColumnName(&res, i)
@@ -89,6 +107,7 @@ func (r *rows) Next(dst []driver.Value) error {
 		}
 	}
 	r.rowCount++
+	r.totalRowCount++
 
 	return nil
 }
@@ -165,13 +184,31 @@ func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
 }
 
 func (r *rows) Close() error {
+	err := r.closeResult()
+	if r.sets != nil {
+		mapping.DestroyExtracted(&r.sets.stmts)
+		r.sets = nil
+	}
+	return err
+}
+
+// closeResult releases the current result set of the rows.
+func (r *rows) closeResult() error {
 	if r.closeChunk {
 		r.chunk.close()
+		r.closeChunk = false
 	}
 	mapping.DestroyResult(&r.res)
 
 	var err error
 	if r.stmt != nil {
+		if !r.created.IsZero() {
+			r.stmt.conn.connector.onRowsClose(r.stmt.hookCtx, r.stmt.hookInfo, RowsResult{
+				Duration: time.Since(r.created),
+				Rows:     r.totalRowCount,
+				Chunks:   int(r.chunkIdx),
+			})
+		}
 		r.stmt.rows = false
 		if r.stmt.closeOnRowsClose {
 			err = r.stmt.Close()
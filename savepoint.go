@@ -0,0 +1,279 @@
+package duckdb
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// WithSavepoints enables savepoints in the transactions of the Connector's connections.
+// DuckDB does not support savepoints natively, so the connections record the statements of their transactions.
+// Rolling back to a savepoint rolls back the transaction, begins a new one,
+// and replays the statements executed before the savepoint.
+//
+// Enabling savepoints retains the arguments of all statements until the end of their transaction.
+// The replayed transaction reads a new snapshot of the database, and statements returning rows,
+// e.g., SELECT statements, are not replayed, unless they call functions with side effects, e.g., nextval.
+//
+// Replaying a statement only reproduces its effects if the statement is deterministic.
+// Statements calling non-deterministic functions, e.g., random(), now(), nextval(), or uuid(),
+// statements reading files, e.g., with read_csv(), and COPY statements, cannot be replayed.
+// Rolling back to a savepoint created after such a statement fails, and leaves the transaction unchanged.
+// The connections cannot detect other non-replayable statements, e.g., statements reading tables
+// changed by other connections since the transaction began, column defaults calling non-deterministic functions,
+// or user-defined functions. Replaying fails, if a statement changes a different number of rows than before.
+// If replaying fails, the transaction is aborted, so that it can only roll back.
+func WithSavepoints() ConnectorOption {
+	return func(opts *connectorOptions) {
+		opts.savepoints = true
+	}
+}
+
+// txLog records the statements of a transaction to emulate savepoints.
+type txLog struct {
+	// The statement that began the transaction.
+	begin string
+	// The executed statements of the transaction.
+	stmts []loggedStmt
+	// The savepoints of the transaction, from oldest to newest.
+	savepoints []savepoint
+	// True, if the transaction appended rows, which cannot be replayed.
+	appended bool
+}
+
+// loggedStmt is an executed statement of a transaction.
+type loggedStmt struct {
+	// The query containing the statement.
+	query string
+	// The index of the statement in the query.
+	index mapping.IdxT
+	// The arguments of the statement.
+	args []driver.NamedValue
+	// The number of rows changed by the statement.
+	rowsChanged int64
+	// The reason why the statement cannot be replayed, or an empty string.
+	notReplayable string
+}
+
+// savepoint is a named position in the statements of a transaction.
+type savepoint struct {
+	name string
+	pos  int
+}
+
+// Savepoint creates a savepoint in the current transaction of a *sql.Conn connection.
+// A savepoint with the same name as an existing savepoint hides the existing savepoint.
+// The Connector must enable savepoints with WithSavepoints.
+func Savepoint(c *sql.Conn, name string) error {
+	return withTxLog(c, func(conn *Conn, log *txLog) error {
+		log.savepoints = append(log.savepoints, savepoint{name: name, pos: len(log.stmts)})
+		return nil
+	})
+}
+
+// RollbackTo rolls back the current transaction of a *sql.Conn connection to the savepoint name.
+// It destroys all savepoints created after the savepoint, and keeps the savepoint.
+// RollbackTo also recovers transactions aborted by a failed statement.
+// It fails without changing the transaction, if a statement executed before the savepoint cannot be replayed,
+// see WithSavepoints.
+func RollbackTo(c *sql.Conn, name string) error {
+	return withTxLog(c, func(conn *Conn, log *txLog) error {
+		i, err := log.find(name)
+		if err != nil {
+			return err
+		}
+		if log.appended {
+			return errors.Join(errSavepoint, errSavepointAppended)
+		}
+		for _, logged := range log.stmts[:log.savepoints[i].pos] {
+			if logged.notReplayable != "" {
+				return errors.Join(errSavepoint, notReplayableError(logged.notReplayable))
+			}
+		}
+
+		log.savepoints = log.savepoints[:i+1]
+		log.stmts = slices.Delete(log.stmts, log.savepoints[i].pos, len(log.stmts))
+		return conn.replay(log)
+	})
+}
+
+// Release destroys the savepoint name and all savepoints created after it
+// in the current transaction of a *sql.Conn connection.
+// It keeps the effects of all statements executed after the savepoint.
+func Release(c *sql.Conn, name string) error {
+	return withTxLog(c, func(conn *Conn, log *txLog) error {
+		i, err := log.find(name)
+		if err != nil {
+			return err
+		}
+		log.savepoints = log.savepoints[:i]
+		return nil
+	})
+}
+
+// withTxLog executes fn with the transaction log of a *sql.Conn connection.
+func withTxLog(c *sql.Conn, fn func(conn *Conn, log *txLog) error) error {
+	return c.Raw(func(driverConn any) error {
+		conn := driverConn.(*Conn)
+		if conn.closed {
+			return errClosedCon
+		}
+		if !conn.connector.savepoints {
+			return errors.Join(errSavepoint, errSavepointsDisabled)
+		}
+		if !conn.tx || conn.txLog == nil {
+			return errors.Join(errSavepoint, errNoTx)
+		}
+		return fn(conn, conn.txLog)
+	})
+}
+
+// find returns the index of the newest savepoint name.
+func (log *txLog) find(name string) (int, error) {
+	for i := len(log.savepoints) - 1; i >= 0; i-- {
+		if log.savepoints[i].name == name {
+			return i, nil
+		}
+	}
+	return 0, errors.Join(errSavepoint, unknownSavepointError(name))
+}
+
+// add records an executed statement of the transaction and the number of rows it changed.
+// It skips statements returning rows, unless they have side effects.
+func (log *txLog) add(s *Stmt, t StmtType, rowsChanged int64) {
+	logged := loggedStmt{query: s.query, index: s.index, args: s.args, rowsChanged: rowsChanged}
+	if t == STATEMENT_TYPE_COPY {
+		logged.notReplayable = "COPY"
+	} else {
+		logged.notReplayable = nonReplayableFunc(stmtText(s.query, s.index))
+	}
+
+	switch t {
+	case STATEMENT_TYPE_SELECT, STATEMENT_TYPE_EXPLAIN:
+		if !sideEffectFuncs[logged.notReplayable] {
+			return
+		}
+	}
+	log.stmts = append(log.stmts, logged)
+}
+
+// nonReplayableFuncs contains the functions whose results differ when replaying a statement.
+var nonReplayableFuncs = map[string]bool{
+	"random": true, "setseed": true, "uuid": true, "gen_random_uuid": true, "uuidv4": true, "uuidv7": true,
+	"now": true, "get_current_time": true, "get_current_timestamp": true, "transaction_timestamp": true,
+	"current_date": true, "current_time": true, "current_timestamp": true, "today": true,
+	"nextval": true, "currval": true, "setval": true,
+	"read_csv": true, "read_csv_auto": true, "read_parquet": true, "parquet_scan": true,
+	"read_json": true, "read_json_auto": true, "read_ndjson": true, "read_text": true, "read_blob": true,
+	"glob": true, "sqlite_scan": true, "postgres_scan": true, "mysql_scan": true,
+}
+
+// nonReplayableKeywords contains the non-replayable functions that can be called without parentheses.
+var nonReplayableKeywords = map[string]bool{
+	"current_date": true, "current_time": true, "current_timestamp": true, "localtime": true, "localtimestamp": true,
+}
+
+// sideEffectFuncs contains the non-replayable functions changing the state of the database.
+var sideEffectFuncs = map[string]bool{
+	"nextval": true, "setval": true, "setseed": true,
+}
+
+// nonReplayableFunc returns the name of the first non-replayable function of a statement, or an empty string.
+// It does not consider quoted strings and identifiers, and comments.
+func nonReplayableFunc(stmt string) string {
+	for i := 0; i < len(stmt); {
+		switch c := stmt[i]; {
+		case strings.HasPrefix(stmt[i:], "--"):
+			i = skipUntil(stmt, i+2, "\n")
+		case strings.HasPrefix(stmt[i:], "/*"):
+			i = skipUntil(stmt, i+2, "*/")
+		case isIdentChar(c) && !isDigit(c):
+			j := i
+			for j < len(stmt) && isIdentChar(stmt[j]) {
+				j++
+			}
+			// Skip qualified names, e.g., t.random, and names that are not function calls, e.g., a column now.
+			name := strings.ToLower(stmt[i:j])
+			qualified := i != 0 && stmt[i-1] == '.'
+			call := strings.HasPrefix(strings.TrimLeft(stmt[j:], " \t\r\n"), "(")
+			if !qualified && (nonReplayableKeywords[name] || (nonReplayableFuncs[name] && call)) {
+				return name
+			}
+			i = j
+		default:
+			i = skipToken(stmt, i)
+		}
+	}
+	return ""
+}
+
+// stmtText returns the text of the statement i of a query.
+// It returns the whole query, if it cannot locate the statement.
+func stmtText(query string, i mapping.IdxT) string {
+	offsets := scriptOffsets(query)
+	if int(i) >= len(offsets) {
+		return query
+	}
+	if int(i) == len(offsets)-1 {
+		return query[offsets[i]:]
+	}
+	return query[offsets[i]:offsets[i+1]]
+}
+
+// replay rolls back the current transaction, begins a new one, and executes the statements of log.
+// It bypasses the hooks and the statistics of the connector.
+// If a statement fails, or changes a different number of rows than before, replay aborts the new transaction.
+func (conn *Conn) replay(log *txLog) error {
+	if _, err := conn.queryInternal(`ROLLBACK`); err != nil {
+		return err
+	}
+	if _, err := conn.queryInternal(log.begin); err != nil {
+		conn.tx = false
+		conn.txLog = nil
+		return err
+	}
+
+	for _, logged := range log.stmts {
+		if err := conn.replayStmt(logged); err != nil {
+			// Abort the partially replayed transaction, so that it cannot commit.
+			// DuckDB aborts transactions on failing statements, so this statement fails on purpose.
+			_, _ = conn.queryInternal(`SELECT error('savepoint replay failed')`)
+			return errors.Join(errSavepoint, errSavepointReplay, err)
+		}
+	}
+	return nil
+}
+
+// replayStmt executes a logged statement, and returns an error, if it changes a different number of rows.
+func (conn *Conn) replayStmt(logged loggedStmt) error {
+	var stmts mapping.ExtractedStatements
+	defer mapping.DestroyExtracted(&stmts)
+	if mapping.ExtractStatements(conn.conn, logged.query, &stmts) == 0 {
+		return getDuckDBError(mapping.ExtractStatementsError(stmts))
+	}
+
+	var preparedStmt mapping.PreparedStatement
+	defer mapping.DestroyPrepare(&preparedStmt)
+	if mapping.PrepareExtractedStatement(conn.conn, stmts, logged.index, &preparedStmt) == mapping.StateError {
+		return getDuckDBError(mapping.PrepareError(preparedStmt))
+	}
+
+	s := &Stmt{conn: conn, preparedStmt: &preparedStmt}
+	if err := s.bind(logged.args); err != nil {
+		return err
+	}
+
+	var res mapping.Result
+	defer mapping.DestroyResult(&res)
+	if mapping.ExecutePrepared(preparedStmt, &res) == mapping.StateError {
+		return getDuckDBError(mapping.ResultError(&res))
+	}
+	if rowsChanged := int64(mapping.RowsChanged(&res)); rowsChanged != logged.rowsChanged {
+		return replayRowsChangedError(logged.rowsChanged, rowsChanged)
+	}
+	return nil
+}
@@ -21,6 +21,8 @@ import (
 	"database/sql/driver"
 	"runtime"
 	"runtime/cgo"
+	"sync/atomic"
+	"time"
 	"unsafe"
 
 	"github.com/marcboeker/go-duckdb/mapping"
@@ -52,6 +54,19 @@ type ScalarFuncConfig struct {
 // bindInfo holds bind data accessible during execution.
 type bindInfo struct {
 	connId uint64
+	// The Connector of the executing connection, once resolved.
+	connector atomic.Pointer[Connector]
+}
+
+// executingConnector returns the Connector of the executing connection.
+// It resolves the Connector once, as the bind callback cannot access the registering Connector.
+func (info *bindInfo) executingConnector(registering *Connector) *Connector {
+	if c := info.connector.Load(); c != nil {
+		return c
+	}
+	c := registering.executingConnector(info.connId)
+	info.connector.Store(c)
+	return c
 }
 
 type (
@@ -83,8 +98,9 @@ type ScalarFunc interface {
 
 // scalarFuncContext wraps ScalarFunc and provides an execution context.
 type scalarFuncContext struct {
-	f        ScalarFunc
-	ctxStore *contextStore
+	f         ScalarFunc
+	name      string
+	connector *Connector
 }
 
 // Config returns the ScalarFuncConfig of the scalar function.
@@ -99,7 +115,7 @@ func (s *scalarFuncContext) RowExecutor(info *bindInfo) RowExecutorFn {
 	if e.RowExecutor != nil {
 		return e.RowExecutor
 	}
-	ctx := s.ctxStore.load(info.connId)
+	ctx := info.executingConnector(s.connector).ctxStore.load(info.connId)
 
 	return func(values []driver.Value) (any, error) {
 		return e.RowContextExecutor(ctx, values)
@@ -111,22 +127,11 @@ func (s *scalarFuncContext) RowExecutor(info *bindInfo) RowExecutorFn {
 // name is the function name, and f is the scalar function's interface ScalarFunc.
 // RegisterScalarUDF takes ownership of f, so you must pass it as a pointer.
 func RegisterScalarUDF(c *sql.Conn, name string, f ScalarFunc) error {
-	function, err := createScalarFunc(c, name, f)
+	connector, err := connectorFromConn(c)
 	if err != nil {
 		return getError(errAPI, err)
 	}
-	defer mapping.DestroyScalarFunction(&function)
-
-	// Register the function on the underlying driver connection exposed by c.Raw.
-	err = c.Raw(func(driverConn any) error {
-		conn := driverConn.(*Conn)
-		state := mapping.RegisterScalarFunction(conn.conn, function)
-		if state == mapping.StateError {
-			return getError(errAPI, errScalarUDFCreate)
-		}
-		return nil
-	})
-	return err
+	return registerScalarUDF(connector, rawConn(c), name, f)
 }
 
 // RegisterScalarUDFSet registers a set of user-defined scalar functions with the same name.
@@ -136,11 +141,48 @@ func RegisterScalarUDF(c *sql.Conn, name string, f ScalarFunc) error {
 // name is the function name of each function in the set.
 // functions contains all ScalarFunc functions of the scalar function set.
 func RegisterScalarUDFSet(c *sql.Conn, name string, functions ...ScalarFunc) error {
+	connector, err := connectorFromConn(c)
+	if err != nil {
+		return getError(errAPI, err)
+	}
+	return registerScalarUDFSet(connector, rawConn(c), name, functions...)
+}
+
+// RegisterScalarUDF registers a user-defined scalar function on the database of the Connector,
+// so that it is visible to all of its connections, and to those of Connectors sharing the database.
+// RegisterScalarUDF takes ownership of f, so you must pass it as a pointer.
+func (c *Connector) RegisterScalarUDF(name string, f ScalarFunc) error {
+	return registerScalarUDF(c, c.withConn, name, f)
+}
+
+// RegisterScalarUDFSet registers a set of user-defined scalar functions with the same name
+// on the database of the Connector, like Connector.RegisterScalarUDF.
+func (c *Connector) RegisterScalarUDFSet(name string, functions ...ScalarFunc) error {
+	return registerScalarUDFSet(c, c.withConn, name, functions...)
+}
+
+func registerScalarUDF(connector *Connector, withConn connFunc, name string, f ScalarFunc) error {
+	function, err := createScalarFunc(connector, name, f)
+	if err != nil {
+		return getError(errAPI, err)
+	}
+	defer mapping.DestroyScalarFunction(&function)
+
+	return withConn(func(conn mapping.Connection) error {
+		state := mapping.RegisterScalarFunction(conn, function)
+		if state == mapping.StateError {
+			return getError(errAPI, errScalarUDFCreate)
+		}
+		return nil
+	})
+}
+
+func registerScalarUDFSet(connector *Connector, withConn connFunc, name string, functions ...ScalarFunc) error {
 	set := mapping.CreateScalarFunctionSet(name)
 
 	// Create each function and add it to the set.
 	for i, f := range functions {
-		function, err := createScalarFunc(c, name, f)
+		function, err := createScalarFunc(connector, name, f)
 		if err != nil {
 			mapping.DestroyScalarFunctionSet(&set)
 			return getError(errAPI, err)
@@ -153,18 +195,15 @@ func RegisterScalarUDFSet(c *sql.Conn, name string, functions ...ScalarFunc) err
 			return getError(errAPI, addIndexToError(errScalarUDFAddToSet, i))
 		}
 	}
+	defer mapping.DestroyScalarFunctionSet(&set)
 
-	// Register the function set on the underlying driver connection exposed by c.Raw.
-	err := c.Raw(func(driverConn any) error {
-		conn := driverConn.(*Conn)
-		state := mapping.RegisterScalarFunctionSet(conn.conn, set)
-		mapping.DestroyScalarFunctionSet(&set)
+	return withConn(func(conn mapping.Connection) error {
+		state := mapping.RegisterScalarFunctionSet(conn, set)
 		if state == mapping.StateError {
 			return getError(errAPI, errScalarUDFCreateSet)
 		}
 		return nil
 	})
-	return err
 }
 
 //export scalar_udf_callback
@@ -189,12 +228,34 @@ func scalar_udf_callback(functionInfoPtr, inputPtr, outputPtr unsafe.Pointer) {
 
 	extraInfo := mapping.ScalarFunctionGetExtraInfo(functionInfo)
 	function := getPinned[*scalarFuncContext](extraInfo)
-	nullInNullOut := !function.Config().SpecialNullHandling
 
 	bindDataPtr := mapping.ScalarFunctionGetBindData(functionInfo)
 	info := getPinned[*bindInfo](bindDataPtr)
 
-	f := function.RowExecutor(info)
+	start := time.Now()
+	err := function.executeChunk(info, &inputChunk, &outputChunk)
+	if err != nil {
+		mapping.ScalarFunctionSetError(functionInfo, getError(errAPI, err).Error())
+	}
+
+	callInfo := UDFCallInfo{
+		Name:     function.name,
+		Rows:     inputChunk.GetSize(),
+		Duration: time.Since(start),
+		Err:      err,
+		ConnId:   info.connId,
+	}
+	connector := info.executingConnector(function.connector)
+	connector.stats.recordUDFCall(callInfo)
+	if len(connector.hooks) != 0 {
+		connector.onUDFCall(connector.ctxStore.load(info.connId), callInfo)
+	}
+}
+
+// executeChunk executes the scalar function for each row of the input chunk.
+func (s *scalarFuncContext) executeChunk(info *bindInfo, inputChunk, outputChunk *DataChunk) error {
+	nullInNullOut := !s.Config().SpecialNullHandling
+	f := s.RowExecutor(info)
 	values := make([]driver.Value, len(inputChunk.columns))
 
 	// Execute the user-defined scalar function for each row.
@@ -204,15 +265,13 @@ func scalar_udf_callback(functionInfoPtr, inputPtr, outputPtr unsafe.Pointer) {
 		nullRow := false
 		for colIdx := 0; colIdx < len(values); colIdx++ {
 			if values[colIdx], err = inputChunk.GetValue(colIdx, rowIdx); err != nil {
-				mapping.ScalarFunctionSetError(functionInfo, getError(errAPI, err).Error())
-				return
+				return err
 			}
 
 			// NULL handling.
 			if nullInNullOut && values[colIdx] == nil {
 				if err = outputChunk.SetValue(0, rowIdx, nil); err != nil {
-					mapping.ScalarFunctionSetError(functionInfo, getError(errAPI, err).Error())
-					return
+					return err
 				}
 				nullRow = true
 				break
@@ -224,17 +283,17 @@ func scalar_udf_callback(functionInfoPtr, inputPtr, outputPtr unsafe.Pointer) {
 		}
 
 		// Execute the user-defined scalar function.
-		if val, e := f(values); e != nil {
-			mapping.ScalarFunctionSetError(functionInfo, getError(errAPI, e).Error())
-			return
-		} else {
-			// Write the result to the output chunk.
-			if err = outputChunk.SetValue(0, rowIdx, val); err != nil {
-				mapping.ScalarFunctionSetError(functionInfo, getError(errAPI, err).Error())
-				return
-			}
+		val, err := f(values)
+		if err != nil {
+			return err
+		}
+		// Write the result to the output chunk.
+		if err = outputChunk.SetValue(0, rowIdx, val); err != nil {
+			return err
 		}
 	}
+
+	return nil
 }
 
 //export scalar_udf_delete_callback
@@ -248,7 +307,8 @@ func scalar_udf_delete_callback(info unsafe.Pointer) {
 func scalar_udf_bind_copy_callback(dataPtr unsafe.Pointer) unsafe.Pointer {
 	// Copy and pin the bind data.
 	data := getPinned[*bindInfo](dataPtr)
-	dataCopy := *data
+	dataCopy := bindInfo{connId: data.connId}
+	dataCopy.connector.Store(data.connector.Load())
 
 	value := pinnedValue[*bindInfo]{
 		pinner: &runtime.Pinner{},
@@ -273,7 +333,7 @@ func scalar_udf_bind_callback(bindInfoPtr unsafe.Pointer) {
 	defer mapping.DestroyClientContext(&ctx)
 
 	id := mapping.ClientContextGetConnectionId(ctx)
-	data := bindInfo{connId: uint64(id)}
+	data := &bindInfo{connId: uint64(id)}
 
 	// Set the copy callback of the bind info.
 	copyPtr := unsafe.Pointer(C.scalar_udf_bind_copy_callback_t(C.scalar_udf_bind_copy_callback))
@@ -282,7 +342,7 @@ func scalar_udf_bind_callback(bindInfoPtr unsafe.Pointer) {
 	// Pin the bind data.
 	value := pinnedValue[*bindInfo]{
 		pinner: &runtime.Pinner{},
-		value:  &data,
+		value:  data,
 	}
 	h := cgo.NewHandle(value)
 	value.pinner.Pin(&h)
@@ -333,7 +393,7 @@ func registerResultParams(config ScalarFuncConfig, f mapping.ScalarFunction) err
 	return nil
 }
 
-func createScalarFunc(c *sql.Conn, name string, f ScalarFunc) (mapping.ScalarFunction, error) {
+func createScalarFunc(connector *Connector, name string, f ScalarFunc) (mapping.ScalarFunction, error) {
 	if name == "" {
 		return mapping.ScalarFunction{}, errScalarUDFNoName
 	}
@@ -373,17 +433,10 @@ func createScalarFunc(c *sql.Conn, name string, f ScalarFunc) (mapping.ScalarFun
 	functionPtr := unsafe.Pointer(C.scalar_udf_callback_t(C.scalar_udf_callback))
 	mapping.ScalarFunctionSetFunction(function, functionPtr)
 
-	// Get the context store of the connection.
-	ctxStore, err := contextStoreFromConn(c)
-	if err != nil {
-		mapping.DestroyScalarFunction(&function)
-		return function, err
-	}
-
 	// Pin the ScalarFunc f.
 	value := pinnedValue[*scalarFuncContext]{
 		pinner: &runtime.Pinner{},
-		value:  &scalarFuncContext{f: f, ctxStore: ctxStore},
+		value:  &scalarFuncContext{f: f, name: name, connector: connector},
 	}
 	h := cgo.NewHandle(value)
 	value.pinner.Pin(&h)
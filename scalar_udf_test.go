@@ -6,6 +6,7 @@ import (
 	"database/sql/driver"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -562,3 +563,73 @@ func TestErrScalarUDFClosedConn(t *testing.T) {
 	err = RegisterScalarUDF(conn, "closed_con", errClosedConUDF)
 	require.ErrorContains(t, err, sql.ErrConnDone.Error())
 }
+
+func TestConnectorScalarUDF(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	// Open a connection before registering the function.
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	var err error
+	currentInfo, err = NewTypeInfo(TYPE_INTEGER)
+	require.NoError(t, err)
+
+	var udf *simpleSUDF
+	require.NoError(t, c.RegisterScalarUDF("my_sum", udf))
+	var types *typesSUDF
+	require.NoError(t, c.RegisterScalarUDFSet("my_addition", udf, types))
+
+	// The functions are visible to the existing and to new connections.
+	var sum int
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT my_sum(10, 42)`).Scan(&sum))
+	require.Equal(t, 52, sum)
+
+	newConn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, newConn)
+	require.NoError(t, newConn.QueryRowContext(context.Background(), `SELECT my_sum(1, 2)`).Scan(&sum))
+	require.Equal(t, 3, sum)
+	require.NoError(t, newConn.QueryRowContext(context.Background(), `SELECT my_addition(42)`).Scan(&sum))
+	require.Equal(t, 42, sum)
+
+	// Errors are reported when registering the function.
+	var errExecutor *errExecutorSUDF
+	err = c.RegisterScalarUDF("err_executor_is_nil", errExecutor)
+	testError(t, err, errAPI.Error(), errScalarUDFCreate.Error(), errScalarUDFNoExecutor.Error())
+
+	closeConnectorWrapper(t, c)
+	err = c.RegisterScalarUDF("closed", udf)
+	testError(t, err, errConnect.Error(), errClosedCon.Error())
+}
+
+func TestSharedDatabaseScalarUDF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "udf.db")
+
+	c1 := newConnectorWrapper(t, path, nil)
+	defer closeConnectorWrapper(t, c1)
+	hooks := &recordingHooks{}
+	c2, err := NewConnectorWithConfig(path, Config{}, WithHooks(hooks))
+	require.NoError(t, err)
+	defer closeConnectorWrapper(t, c2)
+
+	currentInfo, err = NewTypeInfo(TYPE_INTEGER)
+	require.NoError(t, err)
+	var udf *simpleSUDF
+	require.NoError(t, c1.RegisterScalarUDF("my_sum", udf))
+
+	// The function is visible to the connections of c2, which share the database of c1.
+	db := sql.OpenDB(c2)
+	defer closeDbWrapper(t, db)
+	var sum int
+	require.NoError(t, db.QueryRow(`SELECT sum(my_sum(i::INTEGER, 1)) FROM range(3) t(i)`).Scan(&sum))
+	require.Equal(t, 6, sum)
+
+	// The calls are attributed to c2, and its hooks receive the context of the statement.
+	require.Equal(t, uint64(1), c2.Stats().UDFs["my_sum"].Calls)
+	require.Zero(t, c1.Stats().UDFs["my_sum"].Calls)
+	hooks.mu.Lock()
+	defer hooks.mu.Unlock()
+	require.Len(t, hooks.udfCalls, 1)
+}
@@ -0,0 +1,239 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// ScriptOptions configures RunScript.
+type ScriptOptions struct {
+	// Params contains the named parameters of the script.
+	// Each statement binds the parameters it uses, e.g., $name binds Params["name"].
+	// Positional parameters are named by their position, e.g., ? or $1 binds Params["1"].
+	Params map[string]any
+	// ContinueOnError continues executing the script after a statement fails.
+	// By default, RunScript stops at the first failing statement.
+	ContinueOnError bool
+}
+
+// ScriptResult is the result of a statement of a script.
+type ScriptResult struct {
+	// Index is the index of the statement in the script.
+	Index int
+	// Offset is the byte offset of the statement in the script, or -1, if it is unknown.
+	Offset int
+	// Type is the type of the statement.
+	Type StmtType
+	// RowsChanged is the number of rows the statement changed.
+	RowsChanged int64
+	// Columns contains the column names of the statement's result, if it returns rows.
+	Columns []string
+	// Rows contains the rows of the statement's result, if it returns rows.
+	Rows [][]driver.Value
+	// Duration is the execution time of the statement, including reading its rows.
+	Duration time.Duration
+	// Err is the error of the statement, if it failed.
+	Err error
+}
+
+// ScriptError is the error of a failed statement of a script.
+type ScriptError struct {
+	// Index is the index of the statement in the script.
+	Index int
+	// Offset is the byte offset of the statement in the script, or -1, if it is unknown.
+	Offset int
+	// Err is the error of the statement.
+	Err error
+}
+
+func (e *ScriptError) Error() string {
+	return fmt.Sprintf("statement %d at offset %d: %s", e.Index, e.Offset, e.Err.Error())
+}
+
+func (e *ScriptError) Unwrap() error {
+	return e.Err
+}
+
+// RunScript executes the statements of a script on a *sql.Conn connection, and returns a result per executed statement.
+// If a statement fails, RunScript returns a *ScriptError, or, with ContinueOnError, the joined *ScriptError of all failed statements.
+// It returns the results of the statements executed before and after a failure.
+func RunScript(ctx context.Context, c *sql.Conn, script string, opts ScriptOptions) ([]ScriptResult, error) {
+	var results []ScriptResult
+	var errs []error
+
+	err := c.Raw(func(driverConn any) error {
+		conn := driverConn.(*Conn)
+		if conn.closed {
+			return errClosedCon
+		}
+
+		cleanupCtx := conn.setContext(ctx)
+		defer cleanupCtx()
+
+		query, err := conn.connector.beforePrepare(ctx, script)
+		if err != nil {
+			return err
+		}
+
+		stmts, count, err := conn.extractStmts(query)
+		if err != nil {
+			return err
+		}
+		defer mapping.DestroyExtracted(stmts)
+
+		offsets := scriptOffsets(query)
+		if len(offsets) != int(count) {
+			offsets = nil
+		}
+
+		for i := mapping.IdxT(0); i < count; i++ {
+			if err = ctx.Err(); err != nil {
+				return err
+			}
+
+			res := ScriptResult{Index: int(i), Offset: -1}
+			if offsets != nil {
+				res.Offset = offsets[i]
+			}
+
+			start := time.Now()
+			res.Err = conn.runScriptStmt(ctx, *stmts, i, query, opts.Params, &res)
+			res.Duration = time.Since(start)
+			results = append(results, res)
+
+			if res.Err != nil {
+				errs = append(errs, &ScriptError{Index: res.Index, Offset: res.Offset, Err: res.Err})
+				if !opts.ContinueOnError {
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return results, err
+	}
+	return results, errors.Join(errs...)
+}
+
+// runScriptStmt executes the statement i of a script, and records its result in res.
+func (conn *Conn) runScriptStmt(ctx context.Context, stmts mapping.ExtractedStatements, i mapping.IdxT, query string, params map[string]any, res *ScriptResult) error {
+	s, err := conn.prepareExtractedStmt(stmts, i, query)
+	if err != nil {
+		return err
+	}
+	res.Type = StmtType(mapping.PreparedStatementType(*s.preparedStmt))
+
+	args := make([]driver.NamedValue, s.NumInput())
+	for j := range args {
+		name := mapping.ParameterName(*s.preparedStmt, mapping.IdxT(j+1))
+		v, ok := params[name]
+		if !ok {
+			return errors.Join(errCouldNotBind, missingParamError(name), s.Close())
+		}
+		args[j] = driver.NamedValue{Name: name, Ordinal: j + 1, Value: v}
+		if err = conn.convertArg(&args[j]); err != nil {
+			return errors.Join(errCouldNotBind, err, s.Close())
+		}
+	}
+
+	result, err := s.execute(ctx, args)
+	if err != nil {
+		return errors.Join(err, s.Close())
+	}
+	res.RowsChanged = int64(mapping.RowsChanged(result))
+
+	r := newRowsOwningStmt(*result, s)
+	if mapping.ResultReturnType(*result) != mapping.ResultTypeQueryResult {
+		return r.Close()
+	}
+
+	res.Columns = r.Columns()
+	for {
+		row := make([]driver.Value, len(res.Columns))
+		if err = r.Next(row); err != nil {
+			break
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	if errors.Is(err, io.EOF) {
+		err = nil
+	}
+	return errors.Join(err, r.Close())
+}
+
+// scriptOffsets returns the byte offsets of the statements of a script.
+// It skips whitespace and comments before each statement, and skips empty statements.
+func scriptOffsets(script string) []int {
+	var offsets []int
+	start := -1
+
+	for i := 0; i < len(script); {
+		switch c := script[i]; {
+		case c == ';':
+			start = -1
+			i++
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			i++
+		case strings.HasPrefix(script[i:], "--"):
+			i = skipUntil(script, i+2, "\n")
+		case strings.HasPrefix(script[i:], "/*"):
+			i = skipUntil(script, i+2, "*/")
+		default:
+			if start == -1 {
+				start = i
+				offsets = append(offsets, start)
+			}
+			i = skipToken(script, i)
+		}
+	}
+	return offsets
+}
+
+// skipToken returns the offset after the token starting at i.
+// It skips quoted strings and identifiers, and dollar-quoted strings, as a whole.
+func skipToken(script string, i int) int {
+	switch script[i] {
+	case '\'':
+		return skipUntil(script, i+1, "'")
+	case '"':
+		return skipUntil(script, i+1, `"`)
+	case '$':
+		// A dollar-quoted string starts with $tag$, where the tag is empty or an identifier.
+		j := i + 1
+		for j < len(script) && (isIdentChar(script[j]) && (j > i+1 || !isDigit(script[j]))) {
+			j++
+		}
+		if j < len(script) && script[j] == '$' {
+			return skipUntil(script, j+1, script[i:j+1])
+		}
+		return j
+	}
+	return i + 1
+}
+
+// skipUntil returns the offset after the first occurrence of end at or after i, or the length of the script.
+// Doubled quotes within quoted strings and identifiers are two consecutive occurrences, so they are skipped, too.
+func skipUntil(script string, i int, end string) int {
+	j := strings.Index(script[i:], end)
+	if j == -1 {
+		return len(script)
+	}
+	return i + j + len(end)
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,73 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunScript(t *testing.T) {
+	db := openDbWrapper(t, ``)
+	defer closeDbWrapper(t, db)
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	script := `-- Create the table.
+CREATE TABLE t (i INTEGER, s VARCHAR);
+INSERT INTO t VALUES ($i, 'a;b'), ($j, $$c;d$$);
+/* Query the table; */ SELECT i, s FROM t WHERE i >= $j ORDER BY i;
+`
+	params := map[string]any{"i": 1, "j": int8(2)}
+	results, err := RunScript(context.Background(), conn, script, ScriptOptions{Params: params})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+
+	require.Equal(t, STATEMENT_TYPE_CREATE, results[0].Type)
+	require.Equal(t, 21, results[0].Offset)
+	require.Equal(t, STATEMENT_TYPE_INSERT, results[1].Type)
+	require.Equal(t, 60, results[1].Offset)
+	require.Equal(t, int64(2), results[1].RowsChanged)
+	require.Nil(t, results[1].Rows)
+
+	require.Equal(t, STATEMENT_TYPE_SELECT, results[2].Type)
+	require.Equal(t, 2, results[2].Index)
+	require.Equal(t, 132, results[2].Offset)
+	require.Equal(t, []string{"i", "s"}, results[2].Columns)
+	require.Equal(t, [][]driver.Value{{int32(2), "c;d"}}, results[2].Rows)
+	for _, res := range results {
+		require.NoError(t, res.Err)
+		require.Positive(t, res.Duration)
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		script := `SELECT 1; SELECT * FROM missing; SELECT $x; SELECT 4`
+
+		// Stop at the first error.
+		results, err := RunScript(context.Background(), conn, script, ScriptOptions{})
+		require.Len(t, results, 2)
+		var scriptErr *ScriptError
+		require.True(t, errors.As(err, &scriptErr))
+		require.Equal(t, 1, scriptErr.Index)
+		require.Equal(t, 10, scriptErr.Offset)
+		require.ErrorContains(t, err, "missing")
+		require.Equal(t, results[1].Err, scriptErr.Err)
+
+		// Continue after errors.
+		results, err = RunScript(context.Background(), conn, script, ScriptOptions{ContinueOnError: true})
+		require.Len(t, results, 4)
+		require.ErrorContains(t, err, "missing")
+		require.ErrorIs(t, err, errCouldNotBind)
+		require.ErrorContains(t, results[2].Err, missingParamErrMsg+": x")
+		require.Equal(t, [][]driver.Value{{int32(4)}}, results[3].Rows)
+	})
+}
+
+func TestScriptOffsets(t *testing.T) {
+	script := `SELECT 'it''s;'; ;
+		SELECT "a;b" FROM t; -- comment; SELECT
+		/* ; */ SELECT $tag$;$tag$, $1;SELECT 1`
+	require.Equal(t, []int{0, 21, 71, 94}, scriptOffsets(script))
+}
@@ -0,0 +1,326 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// SessionResetOptions configures which session state a Connector resets,
+// before database/sql reuses a connection of its pool.
+// The Connector always rolls back dangling transactions.
+type SessionResetOptions struct {
+	// Settings restores the local settings, including the current schema and the search path,
+	// and the variables of a connection to their values after initializing the connection.
+	Settings bool
+	// TempObjects drops the temporary tables, views, sequences, and macros of a connection,
+	// except for those created while initializing the connection.
+	TempObjects bool
+}
+
+// WithSessionReset resets the session state of connections before database/sql reuses them.
+func WithSessionReset(opts SessionResetOptions) ConnectorOption {
+	return func(connectorOpts *connectorOptions) {
+		connectorOpts.sessionReset = opts
+	}
+}
+
+// sessionState contains the local settings, the variables, and the temporary objects of a connection.
+type sessionState struct {
+	// settings maps the names of the local settings to their values.
+	settings map[string]any
+	// variables maps the names of the variables to their values and types.
+	variables map[string][2]any
+	// tempObjects contains the DROP statements of the temporary objects.
+	tempObjects map[string]struct{}
+}
+
+// IsValid returns false, if the connection is closed or encountered a fatal error.
+// It implements the driver.Validator interface.
+func (conn *Conn) IsValid() bool {
+	return !conn.closed && !conn.fatal
+}
+
+// ResetSession rolls back any dangling transaction of the connection,
+// and resets its session state as configured by WithSessionReset.
+// It returns driver.ErrBadConn, if the connection is invalid or resetting it fails.
+// It implements the driver.SessionResetter interface.
+func (conn *Conn) ResetSession(ctx context.Context) error {
+	if !conn.IsValid() {
+		return driver.ErrBadConn
+	}
+
+	if conn.tx || conn.txDirty {
+		if err := conn.rollback(); err != nil {
+			return errors.Join(driver.ErrBadConn, err)
+		}
+		conn.tx = false
+		conn.txDirty = false
+		conn.txLog = nil
+		conn.txCallbacks = nil
+	}
+
+	opts := conn.connector.sessionReset
+	if opts.Settings && conn.settingsDirty {
+		if err := conn.restoreSession(); err != nil {
+			return errors.Join(driver.ErrBadConn, err)
+		}
+		conn.settingsDirty = false
+		conn.progress = false
+	}
+	if opts.TempObjects && conn.tempDirty {
+		if err := conn.dropTempObjects(); err != nil {
+			return errors.Join(driver.ErrBadConn, err)
+		}
+		conn.tempDirty = false
+	}
+
+	return nil
+}
+
+// recordStmt records an executed statement in the statistics of the connector,
+// and tracks the session state the statement might have changed.
+func (conn *Conn) recordStmt(t StmtType, err error) {
+	conn.connector.stats.recordStmt(t, nil)
+	if err != nil {
+		conn.recordError(err)
+		return
+	}
+
+	switch t {
+	case STATEMENT_TYPE_TRANSACTION:
+		conn.txDirty = true
+	case STATEMENT_TYPE_SET, STATEMENT_TYPE_VARIABLE_SET, STATEMENT_TYPE_PRAGMA:
+		conn.settingsDirty = true
+	case STATEMENT_TYPE_CREATE, STATEMENT_TYPE_CREATE_FUNC:
+		conn.tempDirty = true
+	}
+	if conn.stmtCache != nil && invalidatesStmtCache(t) {
+		conn.connector.invalidateStmtCaches()
+	}
+}
+
+// recordError records an error in the statistics of the connector,
+// and invalidates the connection on fatal errors.
+func (conn *Conn) recordError(err error) {
+	conn.connector.stats.recordError(err)
+
+	var duckdbErr *Error
+	if errors.As(err, &duckdbErr) && duckdbErr.Type == ErrorTypeFatal {
+		conn.fatal = true
+	}
+}
+
+// rollback rolls back the current transaction of the connection, if any.
+func (conn *Conn) rollback() error {
+	_, err := conn.queryInternal(`ROLLBACK`)
+
+	var duckdbErr *Error
+	if errors.As(err, &duckdbErr) && duckdbErr.Type == ErrorTypeTransaction {
+		// There is no active transaction.
+		return nil
+	}
+	return err
+}
+
+// captureSession captures the session state of a new connection, if the connector resets it.
+func (conn *Conn) captureSession() error {
+	opts := conn.connector.sessionReset
+	conn.baseline = &sessionState{}
+
+	var err error
+	if opts.Settings {
+		if conn.baseline, err = conn.sessionState(); err != nil {
+			return err
+		}
+	}
+	if opts.TempObjects {
+		drops, err := conn.tempObjects()
+		if err != nil {
+			return err
+		}
+		conn.baseline.tempObjects = make(map[string]struct{}, len(drops))
+		for _, drop := range drops {
+			conn.baseline.tempObjects[drop] = struct{}{}
+		}
+	}
+
+	// The baseline includes all changes of the initialization.
+	conn.txDirty = false
+	conn.settingsDirty = false
+	conn.tempDirty = false
+	return nil
+}
+
+func (conn *Conn) sessionState() (*sessionState, error) {
+	settings, err := conn.queryInternal(`SELECT name, value FROM duckdb_settings() WHERE scope = 'LOCAL'`)
+	if err != nil {
+		return nil, err
+	}
+	variables, err := conn.queryInternal(`SELECT name, value, type FROM duckdb_variables()`)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &sessionState{
+		settings:  make(map[string]any, len(settings)),
+		variables: make(map[string][2]any, len(variables)),
+	}
+	for _, row := range settings {
+		state.settings[row[0].(string)] = row[1]
+	}
+	for _, row := range variables {
+		state.variables[row[0].(string)] = [2]any{row[1], row[2]}
+	}
+	return state, nil
+}
+
+// restoreSession restores the local settings and the variables of the connection to its baseline.
+func (conn *Conn) restoreSession() error {
+	current, err := conn.sessionState()
+	if err != nil {
+		return err
+	}
+
+	var queries []string
+	for name, value := range current.settings {
+		baseline, ok := conn.baseline.settings[name]
+		if !ok || baseline == value {
+			continue
+		}
+		if baseline == nil {
+			queries = append(queries, fmt.Sprintf(`RESET %s`, quoteIdentifier(name)))
+		} else {
+			queries = append(queries, fmt.Sprintf(`SET %s = %s`, quoteIdentifier(name), quoteString(baseline.(string))))
+		}
+	}
+
+	for name := range current.variables {
+		if _, ok := conn.baseline.variables[name]; !ok {
+			queries = append(queries, fmt.Sprintf(`RESET VARIABLE %s`, quoteIdentifier(name)))
+		}
+	}
+	for name, baseline := range conn.baseline.variables {
+		if value, ok := current.variables[name]; ok && value == baseline {
+			continue
+		}
+		value := `NULL`
+		if baseline[0] != nil {
+			value = quoteString(baseline[0].(string))
+		}
+		queries = append(queries, fmt.Sprintf(`SET VARIABLE %s = CAST(%s AS %s)`, quoteIdentifier(name), value, baseline[1]))
+	}
+
+	for _, query := range queries {
+		if _, err = conn.queryInternal(query); err != nil {
+			return err
+		}
+	}
+
+	// The cached statements might resolve their tables with the restored schema and search path.
+	if conn.stmtCache != nil {
+		conn.stmtCache.clear()
+	}
+	return nil
+}
+
+// dropTempObjects drops the temporary objects of the connection, except for those of its baseline.
+func (conn *Conn) dropTempObjects() error {
+	drops, err := conn.tempObjects()
+	if err != nil {
+		return err
+	}
+
+	for _, drop := range drops {
+		if _, ok := conn.baseline.tempObjects[drop]; ok {
+			continue
+		}
+		if _, err = conn.queryInternal(drop); err != nil {
+			return err
+		}
+	}
+
+	// The cached statements might reference the dropped objects.
+	if conn.stmtCache != nil {
+		conn.stmtCache.clear()
+	}
+	return nil
+}
+
+// tempObjects returns the DROP statements of all temporary objects of the connection.
+// Views and macros precede the tables and sequences they might depend on.
+func (conn *Conn) tempObjects() ([]string, error) {
+	objects, err := conn.queryInternal(`
+		SELECT kind, schema_name, name FROM (
+			SELECT 0 AS priority, 'VIEW' AS kind, schema_name, view_name AS name
+			FROM duckdb_views() WHERE database_name = 'temp' AND NOT internal
+			UNION ALL
+			SELECT 0, CASE function_type WHEN 'table_macro' THEN 'MACRO TABLE' ELSE 'MACRO' END, schema_name, function_name
+			FROM duckdb_functions() WHERE database_name = 'temp' AND function_type IN ('macro', 'table_macro')
+			UNION ALL
+			SELECT 1, 'TABLE', schema_name, table_name FROM duckdb_tables() WHERE database_name = 'temp'
+			UNION ALL
+			SELECT 2, 'SEQUENCE', schema_name, sequence_name FROM duckdb_sequences() WHERE database_name = 'temp'
+		) ORDER BY priority`)
+	if err != nil {
+		return nil, err
+	}
+
+	drops := make([]string, len(objects))
+	for i, object := range objects {
+		drops[i] = fmt.Sprintf(`DROP %s IF EXISTS temp.%s.%s`,
+			object[0], quoteIdentifier(object[1].(string)), quoteIdentifier(object[2].(string)))
+	}
+	return drops, nil
+}
+
+// queryInternal executes a query on the connection and returns all rows of its result.
+// It bypasses the hooks and the statistics of the connector.
+func (conn *Conn) queryInternal(query string) ([][]any, error) {
+	var res mapping.Result
+	defer mapping.DestroyResult(&res)
+	if mapping.Query(conn.conn, query, &res) == mapping.StateError {
+		return nil, getDuckDBError(mapping.ResultError(&res))
+	}
+
+	var rows [][]any
+	chunkCount := mapping.ResultChunkCount(res)
+	for i := mapping.IdxT(0); i < chunkCount; i++ {
+		err := func() error {
+			chunk := DataChunk{}
+			defer chunk.close()
+			if err := chunk.initFromDuckDataChunk(mapping.ResultGetChunk(res, i), false); err != nil {
+				return getError(err, nil)
+			}
+
+			for rowIdx := range chunk.size {
+				row := make([]any, len(chunk.columns))
+				for colIdx := range row {
+					var err error
+					if row[colIdx], err = chunk.GetValue(colIdx, rowIdx); err != nil {
+						return err
+					}
+				}
+				rows = append(rows, row)
+			}
+			return nil
+		}()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func quoteString(s string) string {
+	return `'` + strings.ReplaceAll(s, `'`, `''`) + `'`
+}
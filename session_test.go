@@ -0,0 +1,127 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetSession(t *testing.T) {
+	t.Run("transaction", func(t *testing.T) {
+		c := newConnectorWrapper(t, ``, nil)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		db.SetMaxOpenConns(1)
+		createTable(t, db, `CREATE TABLE t (i INTEGER)`)
+
+		// A transaction started outside of BeginTx does not leak into the next user.
+		_, err := db.Exec(`BEGIN TRANSACTION; INSERT INTO t VALUES (1)`)
+		require.NoError(t, err)
+
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM t`).Scan(&count))
+		require.Equal(t, 0, count)
+		_, err = db.Exec(`COMMIT`)
+		require.Error(t, err)
+
+		// Transactions started with BeginTx keep working.
+		tx, err := db.Begin()
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO t VALUES (2)`)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM t`).Scan(&count))
+		require.Equal(t, 1, count)
+	})
+
+	t.Run("settings", func(t *testing.T) {
+		c, err := NewConnectorWithConfig(``, Config{},
+			WithConnInitFn(func(execer driver.ExecerContext) error {
+				_, err := execer.ExecContext(context.Background(), `SET VARIABLE tenant = 'init'`, nil)
+				return err
+			}),
+			WithSessionReset(SessionResetOptions{Settings: true}))
+		require.NoError(t, err)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		db.SetMaxOpenConns(1)
+		createTable(t, db, `CREATE SCHEMA s`)
+
+		_, err = db.Exec(`USE s`)
+		require.NoError(t, err)
+		_, err = db.Exec(`SET VARIABLE tenant = 'other'`)
+		require.NoError(t, err)
+		_, err = db.Exec(`SET VARIABLE secret = 42`)
+		require.NoError(t, err)
+		_, err = db.Exec(`SET errors_as_json = true`)
+		require.NoError(t, err)
+
+		var schema, tenant, asJSON string
+		var secret sql.NullInt64
+		require.NoError(t, db.QueryRow(`SELECT current_schema(), getvariable('tenant'), getvariable('secret'), current_setting('errors_as_json')`).
+			Scan(&schema, &tenant, &secret, &asJSON))
+		require.Equal(t, "main", schema)
+		require.Equal(t, "init", tenant)
+		require.False(t, secret.Valid)
+		require.Equal(t, "false", asJSON)
+	})
+
+	t.Run("temp objects", func(t *testing.T) {
+		c, err := NewConnectorWithConfig(``, Config{},
+			WithConnInitFn(func(execer driver.ExecerContext) error {
+				_, err := execer.ExecContext(context.Background(), `CREATE TEMP TABLE init AS SELECT 1 AS i`, nil)
+				return err
+			}),
+			WithSessionReset(SessionResetOptions{TempObjects: true}))
+		require.NoError(t, err)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		db.SetMaxOpenConns(1)
+
+		_, err = db.Exec(`
+			CREATE TEMP SEQUENCE seq;
+			CREATE TEMP TABLE t (i INTEGER DEFAULT nextval('seq'));
+			CREATE TEMP VIEW v AS SELECT * FROM t;
+			CREATE TEMP MACRO m(x) AS x + 1`)
+		require.NoError(t, err)
+		_, err = db.Exec(`CREATE TABLE persistent (i INTEGER)`)
+		require.NoError(t, err)
+
+		var names []string
+		r, err := db.Query(`SELECT table_name FROM duckdb_tables() ORDER BY table_name`)
+		require.NoError(t, err)
+		defer closeRowsWrapper(t, r)
+		for r.Next() {
+			var name string
+			require.NoError(t, r.Scan(&name))
+			names = append(names, name)
+		}
+		require.NoError(t, r.Err())
+		require.Equal(t, []string{"init", "persistent"}, names)
+
+		var count int
+		require.NoError(t, db.QueryRow(`SELECT count(*) FROM duckdb_functions() WHERE function_name = 'm'`).Scan(&count))
+		require.Equal(t, 0, count)
+	})
+}
+
+func TestIsValid(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	defer closeConnectorWrapper(t, c)
+
+	driverConn := openDriverConnWrapper(t, c)
+	conn := driverConn.(*Conn)
+	require.True(t, conn.IsValid())
+	require.NoError(t, conn.ResetSession(context.Background()))
+
+	// A fatal error invalidates the connection.
+	conn.recordError(&Error{Type: ErrorTypeFatal, Msg: "FATAL Error: database invalidated"})
+	require.False(t, conn.IsValid())
+	require.ErrorIs(t, conn.ResetSession(context.Background()), driver.ErrBadConn)
+
+	closeDriverConnWrapper(t, &driverConn)
+	require.False(t, conn.IsValid())
+}
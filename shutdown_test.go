@@ -0,0 +1,173 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type shutdownHooks struct {
+	NoopHooks
+	beforeExecute func(info *StmtInfo)
+}
+
+func (h *shutdownHooks) BeforeExecute(ctx context.Context, info *StmtInfo) (context.Context, error) {
+	if h.beforeExecute != nil {
+		h.beforeExecute(info)
+	}
+	return ctx, nil
+}
+
+func TestShutdown(t *testing.T) {
+	t.Run("idle", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "shutdown.db")
+		c := newConnectorWrapper(t, path, nil)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		createTable(t, db, `CREATE TABLE tbl AS SELECT 42 AS i`)
+
+		require.NoError(t, c.Shutdown(context.Background()))
+
+		// The connector no longer creates new connections.
+		_, err := c.Connect(context.Background())
+		testError(t, err, errConnect.Error())
+
+		// A second shutdown is a no-op.
+		require.NoError(t, c.Shutdown(context.Background()))
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		c := newConnectorWrapper(t, ``, nil)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+
+		conn := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, conn)
+
+		errCh := make(chan error)
+		go func() {
+			var count int64
+			errCh <- conn.QueryRowContext(context.Background(),
+				`SELECT count(*) FROM range(50_000_000) t(i) WHERE i % 7 = 0`).Scan(&count)
+		}()
+
+		// Wait for the query to start.
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.activeStmts == 1
+		}, 10*time.Second, time.Millisecond)
+
+		other := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, other)
+		tx, err := db.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+
+		shutdownCh := make(chan error)
+		go func() {
+			shutdownCh <- c.Shutdown(context.Background())
+		}()
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.shutdown
+		}, 10*time.Second, time.Millisecond)
+
+		// Existing connections cannot start new statements while draining.
+		_, err = other.ExecContext(context.Background(), `SELECT 42`)
+		require.ErrorIs(t, err, errShutdown)
+		_, err = tx.Exec(`SELECT 42`)
+		require.ErrorIs(t, err, errShutdown)
+
+		// Open transactions can still end.
+		require.NoError(t, tx.Rollback())
+
+		require.NoError(t, <-shutdownCh)
+		require.NoError(t, <-errCh)
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		hooks := &shutdownHooks{}
+		c, err := NewConnectorWithConfig(``, Config{}, WithHooks(hooks))
+		require.NoError(t, err)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+		createTable(t, db, `CREATE TABLE t (i INTEGER)`)
+
+		driverConn, err := c.Connect(context.Background())
+		require.NoError(t, err)
+		defer closeDriverConnWrapper(t, &driverConn)
+		stmt, err := driverConn.(*Conn).PrepareContext(context.Background(), `INSERT INTO t VALUES (?)`)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, stmt.Close())
+		}()
+
+		// Shut down after the first argument set of the batch.
+		shutdownCh := make(chan error, 1)
+		var inserts int
+		hooks.beforeExecute = func(info *StmtInfo) {
+			if info.Type != STATEMENT_TYPE_INSERT {
+				return
+			}
+			inserts++
+			if inserts != 2 {
+				return
+			}
+			go func() {
+				shutdownCh <- c.Shutdown(context.Background())
+			}()
+			require.Eventually(t, func() bool {
+				c.mu.Lock()
+				defer c.mu.Unlock()
+				return c.shutdown
+			}, 10*time.Second, time.Millisecond)
+		}
+
+		// The batch began before the shutdown, so it executes all argument sets and commits.
+		rowsAffected, err := stmt.(*Stmt).ExecBatchColumns(context.Background(), []int32{1, 2, 3})
+		require.NoError(t, err)
+		require.Equal(t, []int64{1, 1, 1}, rowsAffected)
+		require.NoError(t, <-shutdownCh)
+	})
+
+	t.Run("interrupt", func(t *testing.T) {
+		c := newConnectorWrapper(t, ``, nil)
+		db := sql.OpenDB(c)
+		defer closeDbWrapper(t, db)
+
+		conn := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, conn)
+
+		errCh := make(chan error)
+		go func() {
+			_, err := conn.ExecContext(context.Background(),
+				`CREATE TABLE t AS SELECT range::VARCHAR, random() AS k FROM range(1_000_000_000) ORDER BY k`)
+			errCh <- err
+		}()
+
+		require.Eventually(t, func() bool {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			return c.activeStmts == 1
+		}, 10*time.Second, time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := c.Shutdown(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		// Shutdown does not wait for the interrupted statement.
+		require.Less(t, time.Since(start), 5*time.Second)
+		require.ErrorContains(t, <-errCh, "INTERRUPT Error")
+
+		// The existing connection can no longer execute statements.
+		_, err = conn.ExecContext(context.Background(), `SELECT 42`)
+		require.Error(t, err)
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 	"reflect"
+	"time"
 
 	"github.com/marcboeker/go-duckdb/mapping"
 )
@@ -44,14 +45,61 @@ const (
 	STATEMENT_TYPE_MULTI        = StmtType(mapping.StatementTypeMulti)
 )
 
+var stmtTypeNames = map[StmtType]string{
+	STATEMENT_TYPE_INVALID:      "INVALID",
+	STATEMENT_TYPE_SELECT:       "SELECT",
+	STATEMENT_TYPE_INSERT:       "INSERT",
+	STATEMENT_TYPE_UPDATE:       "UPDATE",
+	STATEMENT_TYPE_EXPLAIN:      "EXPLAIN",
+	STATEMENT_TYPE_DELETE:       "DELETE",
+	STATEMENT_TYPE_PREPARE:      "PREPARE",
+	STATEMENT_TYPE_CREATE:       "CREATE",
+	STATEMENT_TYPE_EXECUTE:      "EXECUTE",
+	STATEMENT_TYPE_ALTER:        "ALTER",
+	STATEMENT_TYPE_TRANSACTION:  "TRANSACTION",
+	STATEMENT_TYPE_COPY:         "COPY",
+	STATEMENT_TYPE_ANALYZE:      "ANALYZE",
+	STATEMENT_TYPE_VARIABLE_SET: "VARIABLE_SET",
+	STATEMENT_TYPE_CREATE_FUNC:  "CREATE_FUNC",
+	STATEMENT_TYPE_DROP:         "DROP",
+	STATEMENT_TYPE_EXPORT:       "EXPORT",
+	STATEMENT_TYPE_PRAGMA:       "PRAGMA",
+	STATEMENT_TYPE_VACUUM:       "VACUUM",
+	STATEMENT_TYPE_CALL:         "CALL",
+	STATEMENT_TYPE_SET:          "SET",
+	STATEMENT_TYPE_LOAD:         "LOAD",
+	STATEMENT_TYPE_RELATION:     "RELATION",
+	STATEMENT_TYPE_EXTENSION:    "EXTENSION",
+	STATEMENT_TYPE_LOGICAL_PLAN: "LOGICAL_PLAN",
+	STATEMENT_TYPE_ATTACH:       "ATTACH",
+	STATEMENT_TYPE_DETACH:       "DETACH",
+	STATEMENT_TYPE_MULTI:        "MULTI",
+}
+
+// String returns the name of the statement type, e.g., "SELECT" for STATEMENT_TYPE_SELECT.
+func (t StmtType) String() string {
+	if name, ok := stmtTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("StmtType(%d)", int(t))
+}
+
 // Stmt implements the driver.Stmt interface.
 type Stmt struct {
 	conn             *Conn
 	preparedStmt     *mapping.PreparedStatement
+	query            string
 	closeOnRowsClose bool
 	bound            bool
 	closed           bool
 	rows             bool
+	// The index of the statement in its query.
+	index mapping.IdxT
+	// The arguments bound to the statement.
+	args []driver.NamedValue
+	// The context and info of the last execution with hooks.
+	hookCtx  context.Context
+	hookInfo StmtInfo
 }
 
 // Close the statement.
@@ -132,6 +180,54 @@ func (s *Stmt) paramLogicalType(n int) (mapping.LogicalType, error) {
 	return mapping.ParamLogicalType(*s.preparedStmt, mapping.IdxT(n)), nil
 }
 
+// ParamTypeInfo returns the type information of the parameter at the given index (1-based),
+// including the details of nested types.
+// For parameters with an unresolved type, its InternalType is TYPE_INVALID.
+func (s *Stmt) ParamTypeInfo(n int) (TypeInfo, error) {
+	lt, err := s.paramLogicalType(n)
+	if err != nil {
+		return nil, err
+	}
+	defer mapping.DestroyLogicalType(&lt)
+	return newTypeInfoFromLogicalType(lt), nil
+}
+
+// ColumnNames returns the names of the result columns of the statement, without executing it.
+func (s *Stmt) ColumnNames() ([]string, error) {
+	if s.closed {
+		return nil, errClosedStmt
+	}
+	if s.preparedStmt == nil {
+		return nil, errUninitializedStmt
+	}
+
+	count := mapping.PreparedStatementColumnCount(*s.preparedStmt)
+	names := make([]string, count)
+	for i := range names {
+		names[i] = mapping.PreparedStatementColumnName(*s.preparedStmt, mapping.IdxT(i))
+	}
+	return names, nil
+}
+
+// ColumnTypeInfos returns the type information of the result columns of the statement, without executing it.
+func (s *Stmt) ColumnTypeInfos() ([]TypeInfo, error) {
+	if s.closed {
+		return nil, errClosedStmt
+	}
+	if s.preparedStmt == nil {
+		return nil, errUninitializedStmt
+	}
+
+	count := mapping.PreparedStatementColumnCount(*s.preparedStmt)
+	infos := make([]TypeInfo, count)
+	for i := range infos {
+		lt := mapping.PreparedStatementColumnLogicalType(*s.preparedStmt, mapping.IdxT(i))
+		infos[i] = newTypeInfoFromLogicalType(lt)
+		mapping.DestroyLogicalType(&lt)
+	}
+	return infos, nil
+}
+
 // StatementType returns the type of the statement.
 func (s *Stmt) StatementType() (StmtType, error) {
 	if s.closed {
@@ -448,6 +544,7 @@ func (s *Stmt) bind(args []driver.NamedValue) error {
 		}
 	}
 
+	s.args = args
 	s.bound = true
 	return nil
 }
@@ -541,17 +638,98 @@ func (s *Stmt) execute(ctx context.Context, args []driver.NamedValue) (*mapping.
 	if s.rows {
 		panic("database/sql/driver: misuse of duckdb driver: ExecContext or QueryContext with active Rows")
 	}
-	if err := s.bind(args); err != nil {
-		return nil, err
+	if len(s.conn.connector.hooks) == 0 {
+		if err := s.bind(args); err != nil {
+			return nil, err
+		}
+		return s.executePending(ctx)
 	}
-	return s.executeBound(ctx)
+
+	info := s.stmtInfo(args)
+	return s.executeWithHooks(ctx, &info, func(ctx context.Context) (*mapping.Result, error) {
+		if err := s.bind(info.Args); err != nil {
+			return nil, err
+		}
+		return s.executePending(ctx)
+	})
 }
 
 func (s *Stmt) executeBound(ctx context.Context) (*mapping.Result, error) {
+	if len(s.conn.connector.hooks) == 0 {
+		return s.executePending(ctx)
+	}
+
+	info := s.stmtInfo(nil)
+	return s.executeWithHooks(ctx, &info, s.executePending)
+}
+
+func (s *Stmt) stmtInfo(args []driver.NamedValue) StmtInfo {
+	return StmtInfo{
+		Query:  s.query,
+		Args:   args,
+		Type:   StmtType(mapping.PreparedStatementType(*s.preparedStmt)),
+		ConnId: s.conn.id,
+	}
+}
+
+// executeWithHooks calls the connector's hooks around executing a statement.
+// It executes the statement with the context returned by the hooks,
+// and keeps that context for the hooks of the returned rows.
+func (s *Stmt) executeWithHooks(ctx context.Context, info *StmtInfo, execFn func(context.Context) (*mapping.Result, error)) (*mapping.Result, error) {
+	start := time.Now()
+
+	var res *mapping.Result
+	ctx, called, err := s.conn.connector.beforeExecute(ctx, info)
+	if err == nil {
+		// Expose the context to user-defined functions.
+		cleanupCtx := s.conn.setContext(ctx)
+		res, err = execFn(ctx)
+		cleanupCtx()
+	}
+
+	stmtRes := StmtResult{Duration: time.Since(start), Err: err}
+	if res != nil {
+		stmtRes.RowsChanged = int64(mapping.RowsChanged(res))
+	}
+	s.conn.connector.afterExecute(ctx, called, *info, stmtRes)
+
+	s.hookCtx = ctx
+	s.hookInfo = *info
+	return res, err
+}
+
+func (s *Stmt) executePending(ctx context.Context) (*mapping.Result, error) {
+	stmtType := StmtType(mapping.PreparedStatementType(*s.preparedStmt))
+
+	if err := s.conn.connector.beginStmt(s.conn.tx && stmtType == STATEMENT_TYPE_TRANSACTION); err != nil {
+		return nil, err
+	}
+	defer s.conn.connector.endStmt()
+	return s.executeRegistered(ctx, stmtType)
+}
+
+// executeRegistered executes the statement with a pending result.
+// The caller must register the statement with the connector's beginStmt.
+func (s *Stmt) executeRegistered(ctx context.Context, stmtType StmtType) (*mapping.Result, error) {
+	// Report the progress of the statement, if the context has a progress callback.
+	// Enabling the progress bar executes queries, so it must happen before creating the pending result.
+	progressFn := progressFromContext(ctx)
+	var progressCh <-chan time.Time
+	if progressFn != nil {
+		if err := s.conn.enableProgress(); err != nil {
+			s.conn.recordStmt(stmtType, err)
+			return nil, err
+		}
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		progressCh = ticker.C
+	}
+
 	var pendingRes mapping.PendingResult
 	if mapping.PendingPrepared(*s.preparedStmt, &pendingRes) == mapping.StateError {
 		dbErr := getDuckDBError(mapping.PendingError(pendingRes))
 		mapping.DestroyPending(&pendingRes)
+		s.conn.recordStmt(stmtType, dbErr)
 		return nil, dbErr
 	}
 	defer mapping.DestroyPending(&pendingRes)
@@ -561,17 +739,23 @@ func (s *Stmt) executeBound(ctx context.Context) (*mapping.Result, error) {
 
 	// go-routine waiting to receive on the context or main channel.
 	go func() {
-		select {
-		// Await an interrupt on the context.
-		case <-ctx.Done():
-			mapping.Interrupt(s.conn.conn)
-			break
-		// Await a done-signal on the main channel.
-		// Reading from a closed channel succeeds immediately.
-		case <-mainDoneCh:
-			break
+		defer close(bgDoneCh)
+		for {
+			select {
+			// Await an interrupt on the context.
+			case <-ctx.Done():
+				mapping.Interrupt(s.conn.conn)
+				return
+			// Await a done-signal on the main channel.
+			// Reading from a closed channel succeeds immediately.
+			case <-mainDoneCh:
+				return
+			// Report the progress periodically.
+			case <-progressCh:
+				progress := s.conn.queryProgress()
+				progressFn(progress.Percentage, progress.RowsProcessed, progress.TotalRows)
+			}
 		}
-		close(bgDoneCh)
 	}()
 
 	var res mapping.Result
@@ -587,13 +771,24 @@ func (s *Stmt) executeBound(ctx context.Context) (*mapping.Result, error) {
 	// If we don't wait for the go-routine to finish, it can cancel that new query.
 	<-bgDoneCh
 
+	return s.pendingResult(ctx, stmtType, state, &res)
+}
+
+// pendingResult records the outcome of executing a pending result,
+// and returns its result, or its error joined with the error of ctx.
+func (s *Stmt) pendingResult(ctx context.Context, stmtType StmtType, state mapping.State, res *mapping.Result) (*mapping.Result, error) {
 	if state == mapping.StateError {
-		err := errors.Join(ctx.Err(), getDuckDBError(mapping.ResultError(&res)))
-		mapping.DestroyResult(&res)
+		err := errors.Join(ctx.Err(), getDuckDBError(mapping.ResultError(res)))
+		mapping.DestroyResult(res)
+		s.conn.recordStmt(stmtType, err)
 		return nil, err
 	}
 
-	return &res, nil
+	s.conn.recordStmt(stmtType, nil)
+	if s.conn.txLog != nil {
+		s.conn.txLog.add(s, stmtType, int64(mapping.RowsChanged(res)))
+	}
+	return res, nil
 }
 
 func argsToNamedArgs(values []driver.Value) []driver.NamedValue {
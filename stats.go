@@ -0,0 +1,217 @@
+package duckdb
+
+import (
+	"errors"
+	"expvar"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Stats contains statistics about the work of a Connector and its connections.
+type Stats struct {
+	// OpenConnections is the number of open connections.
+	OpenConnections int
+	// ActiveQueries is the number of currently executing statements.
+	ActiveQueries int
+	// Statements is the number of executed statements by statement type.
+	Statements map[StmtType]uint64
+	// Errors is the number of DuckDB errors by error type.
+	// It includes errors during preparing and executing statements.
+	Errors map[ErrorType]uint64
+	// AppenderFlushes is the number of appender flushes, including flushes when closing appenders.
+	AppenderFlushes uint64
+	// AppenderRows is the number of flushed appender rows.
+	AppenderRows uint64
+	// StmtCacheHits is the number of queries reusing a cached prepared statement.
+	StmtCacheHits uint64
+	// StmtCacheMisses is the number of queries preparing a statement, if the connections cache prepared statements.
+	StmtCacheMisses uint64
+	// UDFs contains the statistics of the user-defined functions by name.
+	UDFs map[string]UDFStats
+}
+
+// UDFStats contains statistics about a user-defined function.
+type UDFStats struct {
+	// Table is true for table functions, and false for scalar functions.
+	Table bool
+	// Calls is the number of executions of the function on a data chunk.
+	Calls uint64
+	// Rows is the number of input rows of a scalar function, or the number of output rows of a table function.
+	Rows uint64
+	// Errors is the number of failed executions.
+	Errors uint64
+	// Latency is the histogram of the execution times.
+	Latency LatencyHistogram
+}
+
+// LatencyHistogram is a histogram of durations.
+type LatencyHistogram struct {
+	// Bounds are the inclusive upper bounds of the buckets, in increasing order.
+	Bounds []time.Duration
+	// Counts contains the number of durations per bucket.
+	// It contains one more bucket than Bounds, which counts all durations greater than the last bound.
+	Counts []uint64
+	// Count is the total number of durations.
+	Count uint64
+	// Sum is the sum of all durations.
+	Sum time.Duration
+}
+
+// LatencyBounds are the bucket bounds of all latency histograms.
+var LatencyBounds = []time.Duration{
+	10 * time.Microsecond,
+	100 * time.Microsecond,
+	time.Millisecond,
+	10 * time.Millisecond,
+	100 * time.Millisecond,
+	time.Second,
+	10 * time.Second,
+}
+
+func (h *LatencyHistogram) observe(d time.Duration) {
+	if h.Counts == nil {
+		h.Bounds = LatencyBounds
+		h.Counts = make([]uint64, len(LatencyBounds)+1)
+	}
+	i, _ := slices.BinarySearch(h.Bounds, d)
+	h.Counts[i]++
+	h.Count++
+	h.Sum += d
+}
+
+// connectorStats collects the statistics of a Connector.
+type connectorStats struct {
+	mu              sync.Mutex
+	statements      map[StmtType]uint64
+	errors          map[ErrorType]uint64
+	appenderFlushes uint64
+	appenderRows    uint64
+	stmtCacheHits   uint64
+	stmtCacheMisses uint64
+	udfs            map[string]*UDFStats
+}
+
+func newConnectorStats() *connectorStats {
+	return &connectorStats{
+		statements: make(map[StmtType]uint64),
+		errors:     make(map[ErrorType]uint64),
+		udfs:       make(map[string]*UDFStats),
+	}
+}
+
+// recordError counts err, if it is a DuckDB error.
+func (s *connectorStats) recordError(err error) {
+	var duckdbErr *Error
+	if !errors.As(err, &duckdbErr) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.errors[duckdbErr.Type]++
+}
+
+func (s *connectorStats) recordStmt(t StmtType, err error) {
+	s.mu.Lock()
+	s.statements[t]++
+	s.mu.Unlock()
+
+	if err != nil {
+		s.recordError(err)
+	}
+}
+
+func (s *connectorStats) recordAppenderFlush(rows int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.appenderFlushes++
+	s.appenderRows += uint64(rows)
+}
+
+func (s *connectorStats) recordStmtCache(hit bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hit {
+		s.stmtCacheHits++
+	} else {
+		s.stmtCacheMisses++
+	}
+}
+
+func (s *connectorStats) recordUDFCall(info UDFCallInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	udf, ok := s.udfs[info.Name]
+	if !ok {
+		udf = &UDFStats{Table: info.Table}
+		s.udfs[info.Name] = udf
+	}
+	udf.Calls++
+	udf.Rows += uint64(info.Rows)
+	if info.Err != nil {
+		udf.Errors++
+	}
+	udf.Latency.observe(info.Duration)
+}
+
+// Stats returns the statistics of the Connector.
+func (c *Connector) Stats() Stats {
+	c.mu.Lock()
+	stats := Stats{
+		OpenConnections: len(c.conns),
+		ActiveQueries:   c.activeStmts,
+	}
+	c.mu.Unlock()
+
+	s := c.stats
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats.Statements = maps.Clone(s.statements)
+	stats.Errors = maps.Clone(s.errors)
+	stats.AppenderFlushes = s.appenderFlushes
+	stats.AppenderRows = s.appenderRows
+	stats.StmtCacheHits = s.stmtCacheHits
+	stats.StmtCacheMisses = s.stmtCacheMisses
+	stats.UDFs = make(map[string]UDFStats, len(s.udfs))
+	for name, udf := range s.udfs {
+		udfStats := *udf
+		udfStats.Latency.Counts = slices.Clone(udf.Latency.Counts)
+		stats.UDFs[name] = udfStats
+	}
+
+	return stats
+}
+
+// StatsVar returns an expvar.Var exporting the statistics of the Connector as JSON.
+// Statement and error types are keyed by their names.
+// For example, publish the statistics with expvar.Publish("duckdb", c.StatsVar()).
+func (c *Connector) StatsVar() expvar.Var {
+	return expvar.Func(func() any {
+		stats := c.Stats()
+
+		statements := make(map[string]uint64, len(stats.Statements))
+		for t, count := range stats.Statements {
+			statements[t.String()] = count
+		}
+		errs := make(map[string]uint64, len(stats.Errors))
+		for t, count := range stats.Errors {
+			errs[t.String()] = count
+		}
+
+		return map[string]any{
+			"open_connections":  stats.OpenConnections,
+			"active_queries":    stats.ActiveQueries,
+			"statements":        statements,
+			"errors":            errs,
+			"appender_flushes":  stats.AppenderFlushes,
+			"appender_rows":     stats.AppenderRows,
+			"stmt_cache_hits":   stats.StmtCacheHits,
+			"stmt_cache_misses": stats.StmtCacheMisses,
+			"udfs":              stats.UDFs,
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	c, err := NewConnector(``, nil)
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	_, err = conn.ExecContext(context.Background(), `CREATE TABLE t (i INTEGER PRIMARY KEY)`)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(context.Background(), `INSERT INTO t VALUES (1), (2)`)
+	require.NoError(t, err)
+
+	// A catalog error while preparing, and a constraint error while executing.
+	_, err = conn.ExecContext(context.Background(), `SELECT * FROM does_not_exist`)
+	require.Error(t, err)
+	_, err = conn.ExecContext(context.Background(), `INSERT INTO t VALUES (1)`)
+	require.Error(t, err)
+
+	currentInfo, err = NewTypeInfo(TYPE_INTEGER)
+	require.NoError(t, err)
+	var udf *simpleSUDF
+	require.NoError(t, RegisterScalarUDF(conn, "my_sum", udf))
+	var sum int64
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT sum(my_sum(i, 1)) FROM t`).Scan(&sum))
+	require.Equal(t, int64(5), sum)
+
+	require.NoError(t, conn.Raw(func(driverConn any) error {
+		a, err := NewAppenderFromConn(driverConn.(driver.Conn), "", "t")
+		require.NoError(t, err)
+		require.NoError(t, a.AppendRow(int32(3)))
+		require.NoError(t, a.AppendRow(int32(4)))
+		return a.Close()
+	}))
+
+	stats := c.Stats()
+	require.Equal(t, 1, stats.OpenConnections)
+	require.Equal(t, 0, stats.ActiveQueries)
+	require.Equal(t, uint64(1), stats.Statements[STATEMENT_TYPE_CREATE])
+	require.Equal(t, uint64(2), stats.Statements[STATEMENT_TYPE_INSERT])
+	require.Equal(t, uint64(1), stats.Statements[STATEMENT_TYPE_SELECT])
+	require.Equal(t, uint64(1), stats.Errors[ErrorTypeCatalog])
+	require.Equal(t, uint64(1), stats.Errors[ErrorTypeConstraint])
+	require.Equal(t, uint64(1), stats.AppenderFlushes)
+	require.Equal(t, uint64(2), stats.AppenderRows)
+
+	udfStats := stats.UDFs["my_sum"]
+	require.False(t, udfStats.Table)
+	require.Equal(t, uint64(1), udfStats.Calls)
+	require.Equal(t, uint64(2), udfStats.Rows)
+	require.Equal(t, uint64(1), udfStats.Latency.Count)
+	require.Len(t, udfStats.Latency.Counts, len(LatencyBounds)+1)
+
+	// The returned statistics are a snapshot.
+	stats.Statements[STATEMENT_TYPE_CREATE] = 42
+	require.Equal(t, uint64(1), c.Stats().Statements[STATEMENT_TYPE_CREATE])
+
+	var exported map[string]any
+	require.NoError(t, json.Unmarshal([]byte(c.StatsVar().String()), &exported))
+	require.Equal(t, float64(1), exported["statements"].(map[string]any)["CREATE"])
+	require.Equal(t, float64(1), exported["errors"].(map[string]any)["Catalog"])
+}
+
+func TestLatencyHistogram(t *testing.T) {
+	var h LatencyHistogram
+	h.observe(time.Microsecond)
+	h.observe(10 * time.Microsecond)
+	h.observe(time.Millisecond + 1)
+	h.observe(time.Minute)
+
+	require.Equal(t, LatencyBounds, h.Bounds)
+	require.Equal(t, uint64(2), h.Counts[0])
+	require.Equal(t, uint64(1), h.Counts[3])
+	require.Equal(t, uint64(1), h.Counts[len(h.Counts)-1])
+	require.Equal(t, uint64(4), h.Count)
+	require.Equal(t, time.Minute+time.Millisecond+11*time.Microsecond+1, h.Sum)
+}
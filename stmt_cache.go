@@ -0,0 +1,195 @@
+package duckdb
+
+import (
+	"container/list"
+	"context"
+
+	"github.com/marcboeker/go-duckdb/mapping"
+)
+
+// StmtCacheOptions configures the prepared statement caches of a Connector's connections.
+type StmtCacheOptions struct {
+	// Size is the maximum number of cached prepared statements per connection. It defaults to 100.
+	Size int
+	// Prewarm contains queries to prepare on every new connection.
+	Prewarm []string
+}
+
+// WithStmtCache enables a least-recently-used cache of prepared statements per connection.
+// QueryContext and ExecContext reuse the cached prepared statement of a query, instead of preparing it again.
+// The cache is keyed by the query returned by the BeforePrepare hooks, which still run for every query.
+// Queries with multiple statements are not cached.
+//
+// A statement changing the catalog, e.g., a CREATE, ALTER, or DROP statement,
+// or changing a setting or variable, e.g., a USE or SET statement,
+// invalidates the caches of all connections of the Connector,
+// as prepared statements resolve their tables with the current schema and search path.
+// Stats contains the number of cache hits and misses.
+func WithStmtCache(opts StmtCacheOptions) ConnectorOption {
+	return func(o *connectorOptions) {
+		if opts.Size <= 0 {
+			opts.Size = 100
+		}
+		o.stmtCache = &opts
+	}
+}
+
+// stmtCache is a least-recently-used cache of the prepared statements of a connection.
+type stmtCache struct {
+	size int
+	// The invalidation epoch of the connector when the cache was last valid.
+	epoch uint64
+	// The cached statements, from most to least recently used.
+	lru     *list.List
+	entries map[string]*list.Element
+}
+
+type stmtCacheEntry struct {
+	query string
+	stmt  *Stmt
+}
+
+func newStmtCache(size int, epoch uint64) *stmtCache {
+	return &stmtCache{
+		size:    size,
+		epoch:   epoch,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// get returns the cached statement of a query, if it is not in use.
+func (c *stmtCache) get(query string) (*Stmt, bool) {
+	e, ok := c.entries[query]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(e)
+	s := e.Value.(*stmtCacheEntry).stmt
+	return s, !s.rows
+}
+
+// add caches the statement of a query, and evicts the least recently used statement, if the cache is full.
+// It returns false, if the cache already contains a statement for the query.
+func (c *stmtCache) add(query string, s *Stmt) bool {
+	if _, ok := c.entries[query]; ok {
+		return false
+	}
+	c.entries[query] = c.lru.PushFront(&stmtCacheEntry{query: query, stmt: s})
+
+	if c.lru.Len() > c.size {
+		c.remove(c.lru.Back())
+	}
+	return true
+}
+
+func (c *stmtCache) remove(e *list.Element) {
+	entry := c.lru.Remove(e).(*stmtCacheEntry)
+	delete(c.entries, entry.query)
+
+	// Statements with active rows close after closing their rows.
+	if entry.stmt.rows {
+		entry.stmt.closeOnRowsClose = true
+		return
+	}
+	_ = entry.stmt.Close()
+}
+
+// clear evicts all statements.
+func (c *stmtCache) clear() {
+	for c.lru.Len() != 0 {
+		c.remove(c.lru.Back())
+	}
+}
+
+// invalidatesStmtCache returns true for statement types changing the catalog, the settings, or the variables.
+func invalidatesStmtCache(t StmtType) bool {
+	switch t {
+	case STATEMENT_TYPE_CREATE, STATEMENT_TYPE_CREATE_FUNC, STATEMENT_TYPE_DROP, STATEMENT_TYPE_ALTER,
+		STATEMENT_TYPE_ATTACH, STATEMENT_TYPE_DETACH, STATEMENT_TYPE_LOAD, STATEMENT_TYPE_EXTENSION,
+		STATEMENT_TYPE_SET, STATEMENT_TYPE_VARIABLE_SET:
+		return true
+	}
+	return false
+}
+
+// prepareCached returns the prepared last statement of a query, like prepareStmts,
+// and true, if the statement is cached, so that the caller must not close it.
+func (conn *Conn) prepareCached(ctx context.Context, query string) (*Stmt, bool, error) {
+	cache := conn.stmtCache
+	if cache == nil {
+		s, err := conn.prepareStmts(ctx, query)
+		return s, false, err
+	}
+	if conn.closed {
+		return nil, false, errClosedCon
+	}
+
+	cleanupCtx := conn.setContext(ctx)
+	defer cleanupCtx()
+
+	query, err := conn.connector.beforePrepare(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if s, ok := conn.cachedStmt(query); ok {
+		return s, true, nil
+	}
+
+	s, err := conn.prepareQuery(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+	return s, conn.cacheStmt(query, s), nil
+}
+
+// cachedStmt returns the cached statement of a query returned by the BeforePrepare hooks,
+// and records the cache hit or miss.
+func (conn *Conn) cachedStmt(query string) (*Stmt, bool) {
+	cache := conn.stmtCache
+	if epoch := conn.connector.stmtCacheEpoch.Load(); epoch != cache.epoch {
+		cache.clear()
+		cache.epoch = epoch
+	}
+	s, ok := cache.get(query)
+	conn.connector.stats.recordStmtCache(ok)
+	return s, ok
+}
+
+// cacheStmt caches the prepared statement of a query, and returns true, if the cache owns it.
+func (conn *Conn) cacheStmt(query string, s *Stmt) bool {
+	// Do not cache queries with multiple statements, as their other statements executed during preparation.
+	// Do not cache statements invalidating the cache.
+	if s.index != 0 || invalidatesStmtCache(StmtType(mapping.PreparedStatementType(*s.preparedStmt))) {
+		return false
+	}
+	return conn.stmtCache.add(query, s)
+}
+
+// prewarmStmtCache prepares and caches the prewarm queries of the connector.
+func (conn *Conn) prewarmStmtCache() error {
+	for _, query := range conn.connector.stmtCacheOpts.Prewarm {
+		query, err := conn.connector.beforePrepare(context.Background(), query)
+		if err != nil {
+			return err
+		}
+
+		s, err := conn.prepareQuery(context.Background(), query)
+		if err != nil {
+			return err
+		}
+		if s.index != 0 || !conn.stmtCache.add(query, s) {
+			if err = s.Close(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// invalidateStmtCaches invalidates the prepared statement caches of all connections.
+// Each connection clears its cache before its next lookup.
+func (c *Connector) invalidateStmtCaches() {
+	c.stmtCacheEpoch.Add(1)
+}
@@ -0,0 +1,106 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStmtCache(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithStmtCache(StmtCacheOptions{
+		Size:    2,
+		Prewarm: []string{`SELECT 42`},
+	}))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	var i int
+	query := func(q string, args ...any) int {
+		require.NoError(t, conn.QueryRowContext(context.Background(), q, args...).Scan(&i))
+		return i
+	}
+
+	// Prewarmed queries hit the cache.
+	require.Equal(t, 42, query(`SELECT 42`))
+	stats := c.Stats()
+	require.Equal(t, uint64(1), stats.StmtCacheHits)
+	require.Equal(t, uint64(0), stats.StmtCacheMisses)
+
+	// Repeated queries reuse their prepared statement with new arguments.
+	require.Equal(t, 1, query(`SELECT ?::INTEGER`, 1))
+	require.Equal(t, 2, query(`SELECT ?::INTEGER`, 2))
+	stats = c.Stats()
+	require.Equal(t, uint64(2), stats.StmtCacheHits)
+	require.Equal(t, uint64(1), stats.StmtCacheMisses)
+
+	// A nested query with the same text as a query with active rows prepares a new statement.
+	rows, err := conn.QueryContext(context.Background(), `SELECT 42`)
+	require.NoError(t, err)
+	require.Equal(t, 42, query(`SELECT 42`))
+	closeRowsWrapper(t, rows)
+
+	// The least recently used statement is evicted.
+	require.Equal(t, 3, query(`SELECT 3`))
+	require.Equal(t, 4, query(`SELECT ?::INTEGER`, 4))
+	require.Equal(t, 42, query(`SELECT 42`))
+	stats = c.Stats()
+	require.Equal(t, uint64(3), stats.StmtCacheHits)
+	require.Equal(t, uint64(5), stats.StmtCacheMisses)
+
+	// DDL invalidates the caches.
+	_, err = conn.ExecContext(context.Background(), `CREATE TABLE t (i INTEGER)`)
+	require.NoError(t, err)
+	require.Equal(t, 42, query(`SELECT 42`))
+	stats = c.Stats()
+	require.Equal(t, uint64(3), stats.StmtCacheHits)
+	require.Equal(t, uint64(7), stats.StmtCacheMisses)
+
+	// Multi-statement queries are not cached.
+	require.Equal(t, 1, query(`INSERT INTO t VALUES (1); SELECT count(*) FROM t`))
+	require.Equal(t, 2, query(`INSERT INTO t VALUES (1); SELECT count(*) FROM t`))
+}
+
+func TestStmtCacheSchemaSwitch(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{},
+		WithStmtCache(StmtCacheOptions{}),
+		WithSessionReset(SessionResetOptions{Settings: true}))
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	db.SetMaxOpenConns(1)
+
+	createTable(t, db, `CREATE SCHEMA a; CREATE TABLE a.t AS SELECT 'a' AS tenant;
+		CREATE SCHEMA b; CREATE TABLE b.t AS SELECT 'b' AS tenant;
+		CREATE TABLE main.t AS SELECT 'main' AS tenant`)
+
+	conn := openConnWrapper(t, db, context.Background())
+	tenant := func() string {
+		var s string
+		require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT tenant FROM t`).Scan(&s))
+		return s
+	}
+	exec := func(query string) {
+		_, err := conn.ExecContext(context.Background(), query)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, "main", tenant())
+	exec(`USE a`)
+	require.Equal(t, "a", tenant())
+	exec(`SET schema = 'b'`)
+	require.Equal(t, "b", tenant())
+	exec(`SET search_path = 'a'`)
+	require.Equal(t, "a", tenant())
+	closeConnWrapper(t, conn)
+
+	// Resetting the session restores the schema, and clears the cache.
+	var s string
+	require.NoError(t, db.QueryRow(`SELECT tenant FROM t`).Scan(&s))
+	require.Equal(t, "main", s)
+}
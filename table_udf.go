@@ -24,6 +24,7 @@ import (
 	"database/sql"
 	"runtime"
 	"runtime/cgo"
+	"time"
 	"unsafe"
 
 	"github.com/marcboeker/go-duckdb/mapping"
@@ -50,6 +51,17 @@ type (
 	tableFunctionData struct {
 		fun        any
 		projection []int
+		// The function context, the connection id, and the Connector of the connection of the bound function.
+		funcCtx   *tableFuncContext
+		connId    uint64
+		connector *Connector
+	}
+
+	// tableFuncContext wraps a table function and provides an execution context.
+	tableFuncContext struct {
+		f         any
+		name      string
+		connector *Connector
 	}
 
 	// TableFunctionConfig contains any information passed to DuckDB when registering the table function.
@@ -139,7 +151,8 @@ func table_udf_bind_chunk(infoPtr unsafe.Pointer) {
 func udfBindTyped[T tableSource](infoPtr unsafe.Pointer) {
 	info := mapping.BindInfo{Ptr: infoPtr}
 
-	f := getPinned[tableFunction[T]](mapping.BindGetExtraInfo(info))
+	funcCtx := getPinned[*tableFuncContext](mapping.BindGetExtraInfo(info))
+	f := funcCtx.f.(tableFunction[T])
 	config := f.Config
 
 	argCount := len(config.Arguments)
@@ -176,10 +189,18 @@ func udfBindTyped[T tableSource](infoPtr unsafe.Pointer) {
 		return
 	}
 
+	var clientCtx mapping.ClientContext
+	mapping.TableFunctionGetClientContext(info, &clientCtx)
+	connId := uint64(mapping.ClientContextGetConnectionId(clientCtx))
+	mapping.DestroyClientContext(&clientCtx)
+
 	columnInfos := instance.ColumnInfos()
 	instanceData := tableFunctionData{
 		fun:        instance,
 		projection: make([]int, len(columnInfos)),
+		funcCtx:    funcCtx,
+		connId:     connId,
+		connector:  funcCtx.connector.executingConnector(connId),
 	}
 
 	for i, v := range columnInfos {
@@ -248,6 +269,7 @@ func table_udf_callback(infoPtr, outputPtr unsafe.Pointer) {
 
 	localState := getPinned[any](mapping.FunctionGetLocalInitData(info))
 
+	start := time.Now()
 	switch fun := instance.fun.(type) {
 	case ParallelRowTableSource:
 		row := Row{
@@ -260,6 +282,7 @@ func table_udf_callback(infoPtr, outputPtr unsafe.Pointer) {
 		for row.r = 0; row.r < maxSize; row.r++ {
 			next, errRow := fun.FillRow(localState, row)
 			if errRow != nil {
+				err = errRow
 				mapping.FunctionSetError(info, errRow.Error())
 				break
 			}
@@ -274,6 +297,19 @@ func table_udf_callback(infoPtr, outputPtr unsafe.Pointer) {
 			mapping.FunctionSetError(info, err.Error())
 		}
 	}
+
+	callInfo := UDFCallInfo{
+		Name:     instance.funcCtx.name,
+		Table:    true,
+		Rows:     int(mapping.DataChunkGetSize(output)),
+		Duration: time.Since(start),
+		Err:      err,
+		ConnId:   instance.connId,
+	}
+	instance.connector.stats.recordUDFCall(callInfo)
+	if len(instance.connector.hooks) != 0 {
+		instance.connector.onUDFCall(instance.connector.ctxStore.load(instance.connId), callInfo)
+	}
 }
 
 //export table_udf_delete_callback
@@ -286,36 +322,50 @@ func table_udf_delete_callback(info unsafe.Pointer) {
 // RegisterTableUDF registers a user-defined table function.
 // Projection pushdown is enabled by default.
 func RegisterTableUDF[TFT TableFunction](conn *sql.Conn, name string, f TFT) error {
+	connector, err := connectorFromConn(conn)
+	if err != nil {
+		return getError(errAPI, err)
+	}
+	return registerTableUDF(connector, rawConn(conn), name, f)
+}
+
+// RegisterConnectorTableUDF registers a user-defined table function on the database of the Connector,
+// like Connector.RegisterScalarUDF.
+// Projection pushdown is enabled by default.
+func RegisterConnectorTableUDF[TFT TableFunction](c *Connector, name string, f TFT) error {
+	return registerTableUDF(c, c.withConn, name, f)
+}
+
+func registerTableUDF(connector *Connector, withConn connFunc, name string, f any) error {
 	if name == "" {
 		return getError(errAPI, errTableUDFNoName)
 	}
 
 	// normalise the function
-	var x any = f
-	switch tableFunc := x.(type) {
+	switch tableFunc := f.(type) {
 	case RowTableFunction:
-		return registerParallelTableUDF(conn, name, wrapRowTF(tableFunc))
+		return registerParallelTableUDF(connector, withConn, name, wrapRowTF(tableFunc))
 	case ChunkTableFunction:
-		return registerParallelTableUDF(conn, name, wrapChunkTF(tableFunc))
+		return registerParallelTableUDF(connector, withConn, name, wrapChunkTF(tableFunc))
 	case ParallelRowTableFunction:
-		return registerParallelTableUDF(conn, name, tableFunc)
+		return registerParallelTableUDF(connector, withConn, name, tableFunc)
 	case ParallelChunkTableFunction:
-		return registerParallelTableUDF(conn, name, tableFunc)
+		return registerParallelTableUDF(connector, withConn, name, tableFunc)
 	default:
-		return getError(errInternal, nil)
+		return getError(errAPI, errTableUDFInvalidType)
 	}
 }
 
-func registerParallelTableUDF[TFT parallelTableFunction](conn *sql.Conn, name string, f TFT) error {
+func registerParallelTableUDF[TFT parallelTableFunction](connector *Connector, withConn connFunc, name string, f TFT) error {
 	function := mapping.CreateTableFunction()
 	mapping.TableFunctionSetName(function, name)
 
 	var config TableFunctionConfig
 
 	// Pin the table function f.
-	value := pinnedValue[TFT]{
+	value := pinnedValue[*tableFuncContext]{
 		pinner: &runtime.Pinner{},
-		value:  f,
+		value:  &tableFuncContext{f: f, name: name, connector: connector},
 	}
 	h := cgo.NewHandle(value)
 	value.pinner.Pin(&h)
@@ -379,15 +429,13 @@ func registerParallelTableUDF[TFT parallelTableFunction](conn *sql.Conn, name st
 		mapping.DestroyLogicalType(&logicalType)
 	}
 
-	// Register the function on the underlying driver connection exposed by c.Raw.
-	err := conn.Raw(func(driverConn any) error {
-		c := driverConn.(*Conn)
-		state := mapping.RegisterTableFunction(c.conn, function)
-		mapping.DestroyTableFunction(&function)
+	// Register the function on the connection.
+	defer mapping.DestroyTableFunction(&function)
+	return withConn(func(conn mapping.Connection) error {
+		state := mapping.RegisterTableFunction(conn, function)
 		if state == mapping.StateError {
 			return getError(errAPI, errTableUDFCreate)
 		}
 		return nil
 	})
-	return err
 }
@@ -2,6 +2,7 @@ package duckdb
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"math/big"
 	"sync"
@@ -886,6 +887,32 @@ func TestErrTableUDF(t *testing.T) {
 	// FIXME: add more error tests.
 }
 
+func TestConnectorTableUDF(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+
+	// Open a connection before registering the function.
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	var udf incTableUDF
+	require.NoError(t, RegisterConnectorTableUDF(c, "inc", udf.GetFunction()))
+	var parallelUDF parallelChunkIncTableUDF
+	require.NoError(t, RegisterConnectorTableUDF(c, "parallel_inc", parallelUDF.GetFunction()))
+
+	// The functions are visible to the existing and to new connections.
+	var count int64
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT count(*) FROM inc(100)`).Scan(&count))
+	require.Equal(t, int64(100), count)
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM parallel_inc(2048)`).Scan(&count))
+	require.Equal(t, int64(2048), count)
+
+	// Errors are reported when registering the function.
+	err := RegisterConnectorTableUDF(c, "", udf.GetFunction())
+	testError(t, err, errAPI.Error(), errTableUDFCreate.Error(), errTableUDFNoName.Error())
+}
+
 func TestTableUDFAggregate(t *testing.T) {
 	db := openDbWrapper(t, `?access_mode=READ_WRITE`)
 	defer closeDbWrapper(t, db)
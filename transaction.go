@@ -1,20 +1,120 @@
 package duckdb
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
 
 type tx struct {
 	c *Conn
 }
 
+// TxCallbacks contains functions to run after a transaction commits or rolls back.
+// It is safe for concurrent use.
+type TxCallbacks struct {
+	mu         sync.Mutex
+	onCommit   []func(err error)
+	onRollback []func(err error)
+}
+
+type txCallbacksKey struct{}
+
+// WithTxCallbacks returns a context with callbacks.
+// Transactions begun with the context run the callbacks after they commit or roll back.
+// The callbacks can be registered before beginning the transaction, or at any time during the transaction.
+func WithTxCallbacks(ctx context.Context, callbacks *TxCallbacks) context.Context {
+	return context.WithValue(ctx, txCallbacksKey{}, callbacks)
+}
+
+// OnCommit registers fn to run after COMMIT returns.
+// fn receives the error of the commit, which is nil, if the transaction committed successfully.
+func (cb *TxCallbacks) OnCommit(fn func(err error)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onCommit = append(cb.onCommit, fn)
+}
+
+// OnRollback registers fn to run after ROLLBACK returns.
+// fn receives the error of the rollback.
+func (cb *TxCallbacks) OnRollback(fn func(err error)) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.onRollback = append(cb.onRollback, fn)
+}
+
+// OnCommit registers fn to run after the current transaction of a *sql.Conn connection commits.
+// fn receives the error of the commit, which is nil, if the transaction committed successfully.
+func OnCommit(c *sql.Conn, fn func(err error)) error {
+	return withTxCallbacks(c, func(cb *TxCallbacks) {
+		cb.OnCommit(fn)
+	})
+}
+
+// OnRollback registers fn to run after the current transaction of a *sql.Conn connection rolls back.
+// fn receives the error of the rollback.
+func OnRollback(c *sql.Conn, fn func(err error)) error {
+	return withTxCallbacks(c, func(cb *TxCallbacks) {
+		cb.OnRollback(fn)
+	})
+}
+
+func withTxCallbacks(c *sql.Conn, fn func(cb *TxCallbacks)) error {
+	return c.Raw(func(driverConn any) error {
+		conn := driverConn.(*Conn)
+		if conn.closed {
+			return errClosedCon
+		}
+		if !conn.tx {
+			return errors.Join(errTxCallback, errNoTx)
+		}
+		if conn.txCallbacks == nil {
+			conn.txCallbacks = &TxCallbacks{}
+		}
+		fn(conn.txCallbacks)
+		return nil
+	})
+}
+
+// txCallbacksFromContext returns the callbacks of ctx, if any.
+func txCallbacksFromContext(ctx context.Context) *TxCallbacks {
+	callbacks, _ := ctx.Value(txCallbacksKey{}).(*TxCallbacks)
+	return callbacks
+}
+
+// run runs the callbacks fns with err.
+func (cb *TxCallbacks) run(fns *[]func(err error), err error) {
+	cb.mu.Lock()
+	callbacks := *fns
+	cb.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(err)
+	}
+}
+
 func (t *tx) Commit() error {
 	if t.c == nil || !t.c.tx {
 		panic("database/sql/driver: misuse of duckdb driver: extra Commit")
 	}
 
-	t.c.tx = false
+	t.c.txLog = nil
+	callbacks := t.c.txCallbacks
+	t.c.txCallbacks = nil
+	// The connection remains in the transaction while ending it, so that a draining Connector accepts the statement.
 	_, err := t.c.ExecContext(context.Background(), "COMMIT TRANSACTION", nil)
+	t.c.tx = false
+	if err == nil {
+		t.c.txDirty = false
+	}
 	t.c = nil
 
+	if callbacks != nil {
+		callbacks.run(&callbacks.onCommit, err)
+	}
 	return err
 }
 
@@ -23,9 +123,102 @@ func (t *tx) Rollback() error {
 		panic("database/sql/driver: misuse of duckdb driver: extra Rollback")
 	}
 
-	t.c.tx = false
+	t.c.txLog = nil
+	callbacks := t.c.txCallbacks
+	t.c.txCallbacks = nil
+	// The connection remains in the transaction while ending it, so that a draining Connector accepts the statement.
 	_, err := t.c.ExecContext(context.Background(), "ROLLBACK", nil)
+	t.c.tx = false
+	if err == nil {
+		t.c.txDirty = false
+	}
 	t.c = nil
 
+	if callbacks != nil {
+		callbacks.run(&callbacks.onRollback, err)
+	}
 	return err
 }
+
+// RetryOptions configures RunInTx.
+type RetryOptions struct {
+	// TxOptions are the options of each transaction.
+	TxOptions *sql.TxOptions
+	// MaxAttempts is the maximum number of attempts to run the transaction. It defaults to 5.
+	MaxAttempts int
+	// InitialBackoff is the maximum backoff before the first retry. It defaults to 10ms.
+	// The maximum backoff doubles with each retry, and each backoff is a random duration up to the maximum.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the maximum backoff. It defaults to one second.
+	MaxBackoff time.Duration
+	// ShouldRetry reports whether to retry after an error.
+	// It defaults to retrying transaction conflicts, i.e., errors containing an *Error with ErrorTypeTransaction.
+	ShouldRetry func(err error) bool
+	// OnRetry is called before each retry with the number of the failed attempt, starting at 1,
+	// the error of the failed attempt, and the backoff before the retry.
+	OnRetry func(attempt int, err error, backoff time.Duration)
+}
+
+// RunInTx runs fn in a transaction of db and commits the transaction.
+// If fn or the commit fail with a transaction conflict, RunInTx rolls back the transaction
+// and retries it after a jittered exponential backoff. RetryOptions.ShouldRetry can retry other errors, too.
+// fn must not commit or roll back the transaction, and it must be safe to run fn more than once.
+// RunInTx returns the error of the last attempt, or the context's error, if ctx is done while backing off.
+func RunInTx(ctx context.Context, db *sql.DB, opts RetryOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.InitialBackoff <= 0 {
+		opts.InitialBackoff = 10 * time.Millisecond
+	}
+	if opts.MaxBackoff <= 0 {
+		opts.MaxBackoff = time.Second
+	}
+	if opts.ShouldRetry == nil {
+		opts.ShouldRetry = isTxConflict
+	}
+
+	maxBackoff := opts.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, opts.TxOptions, fn)
+		if err == nil || attempt == opts.MaxAttempts || !opts.ShouldRetry(err) {
+			return err
+		}
+
+		backoff := rand.N(maxBackoff + 1)
+		maxBackoff = min(2*maxBackoff, opts.MaxBackoff)
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt, err, backoff)
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// runTx runs a single attempt of RunInTx.
+func runTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(ctx context.Context, tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if err = fn(ctx, tx); err != nil {
+		if errRollback := tx.Rollback(); errRollback != nil && !errors.Is(errRollback, sql.ErrTxDone) {
+			return errors.Join(err, errRollback)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// isTxConflict returns true, if err contains a transaction conflict.
+func isTxConflict(err error) bool {
+	var duckdbErr *Error
+	return errors.As(err, &duckdbErr) && duckdbErr.Type == ErrorTypeTransaction
+}
@@ -0,0 +1,390 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBeginTx(t *testing.T) {
+	db := openDbWrapper(t, ``)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (i INTEGER)`)
+
+	t.Run("read-only", func(t *testing.T) {
+		tx, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+		require.NoError(t, err)
+
+		var count int
+		require.NoError(t, tx.QueryRow(`SELECT count(*) FROM t`).Scan(&count))
+		require.Equal(t, 0, count)
+
+		_, err = tx.Exec(`INSERT INTO t VALUES (1)`)
+		require.ErrorContains(t, err, "read-only")
+		require.NoError(t, tx.Rollback())
+	})
+
+	t.Run("isolation levels", func(t *testing.T) {
+		for _, level := range []sql.IsolationLevel{
+			sql.LevelDefault, sql.LevelSnapshot, sql.LevelRepeatableRead, sql.LevelSerializable,
+		} {
+			tx, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: level})
+			require.NoError(t, err, level.String())
+			_, err = tx.Exec(`INSERT INTO t VALUES (1)`)
+			require.NoError(t, err)
+			require.NoError(t, tx.Commit())
+		}
+
+		for _, level := range []sql.IsolationLevel{
+			sql.LevelReadUncommitted, sql.LevelReadCommitted, sql.LevelWriteCommitted, sql.LevelLinearizable,
+		} {
+			_, err := db.BeginTx(context.Background(), &sql.TxOptions{Isolation: level})
+			require.ErrorIs(t, err, errBeginTx)
+			require.ErrorIs(t, err, errIsolationLevelNotSupported)
+			require.ErrorContains(t, err, level.String())
+		}
+	})
+}
+
+func TestSavepoint(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithSavepoints())
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (i INTEGER PRIMARY KEY)`)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	count := func() int {
+		var count int
+		require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT count(*) FROM t`).Scan(&count))
+		return count
+	}
+	exec := func(query string, args ...any) error {
+		_, err := conn.ExecContext(context.Background(), query, args...)
+		return err
+	}
+
+	tx, err := conn.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback()
+	}()
+	require.NoError(t, exec(`INSERT INTO t VALUES (?)`, 1))
+
+	// Roll back a nested unit of work.
+	require.NoError(t, Savepoint(conn, "a"))
+	require.NoError(t, exec(`INSERT INTO t VALUES (?)`, 2))
+	require.Equal(t, 2, count())
+	require.NoError(t, RollbackTo(conn, "a"))
+	require.Equal(t, 1, count())
+
+	// Recover from a failed statement.
+	require.Error(t, exec(`INSERT INTO t VALUES (?)`, 1))
+	require.Error(t, exec(`INSERT INTO t VALUES (?)`, 3))
+	require.NoError(t, RollbackTo(conn, "a"))
+	require.NoError(t, exec(`INSERT INTO t VALUES (3); INSERT INTO t VALUES (?)`, 4))
+	require.Equal(t, 3, count())
+
+	// Replay multiple statements of a query.
+	require.NoError(t, Savepoint(conn, "b"))
+	require.NoError(t, exec(`INSERT INTO t VALUES (5)`))
+	require.NoError(t, RollbackTo(conn, "b"))
+	require.Equal(t, 3, count())
+
+	// Release keeps the effects of the nested unit of work.
+	require.NoError(t, exec(`INSERT INTO t VALUES (5)`))
+	require.NoError(t, Release(conn, "a"))
+	require.ErrorIs(t, RollbackTo(conn, "b"), errSavepoint)
+	require.ErrorContains(t, RollbackTo(conn, "a"), unknownSavepointErrMsg)
+
+	require.NoError(t, tx.Commit())
+	require.Equal(t, 4, count())
+
+	// Savepoints require a transaction.
+	require.ErrorIs(t, Savepoint(conn, "a"), errNoTx)
+
+	// Savepoints require WithSavepoints.
+	other := openDbWrapper(t, ``)
+	defer closeDbWrapper(t, other)
+	otherConn := openConnWrapper(t, other, context.Background())
+	defer closeConnWrapper(t, otherConn)
+	require.ErrorIs(t, Savepoint(otherConn, "a"), errSavepointsDisabled)
+}
+
+func TestSavepointNotReplayable(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithSavepoints())
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (i INTEGER, d DOUBLE)`)
+	createTable(t, db, `CREATE SEQUENCE seq`)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+	exec := func(query string) {
+		_, err := conn.ExecContext(context.Background(), query)
+		require.NoError(t, err)
+	}
+
+	tx, err := conn.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// Deterministic statements and non-deterministic statements after the savepoint do not prevent a rollback.
+	exec(`INSERT INTO t VALUES (1, 0.5) -- random()`)
+	require.NoError(t, Savepoint(conn, "a"))
+	exec(`INSERT INTO t VALUES (2, random())`)
+	require.NoError(t, RollbackTo(conn, "a"))
+
+	// Non-deterministic statements before the savepoint cannot be replayed.
+	exec(`INSERT INTO t VALUES (3, RANDOM ())`)
+	require.NoError(t, Savepoint(conn, "b"))
+	err = RollbackTo(conn, "b")
+	require.ErrorIs(t, err, errSavepoint)
+	require.ErrorContains(t, err, notReplayableErrMsg)
+	require.ErrorContains(t, err, "random")
+
+	// The transaction is unchanged.
+	var count int
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT count(*) FROM t`).Scan(&count))
+	require.Equal(t, 2, count)
+	require.NoError(t, tx.Rollback())
+
+	// SELECT statements with side effects are logged.
+	tx, err = conn.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	var v int
+	require.NoError(t, conn.QueryRowContext(context.Background(), `SELECT nextval('seq')`).Scan(&v))
+	require.NoError(t, Savepoint(conn, "c"))
+	require.ErrorContains(t, RollbackTo(conn, "c"), "nextval")
+	require.NoError(t, tx.Rollback())
+}
+
+func TestSavepointReplayRowsChanged(t *testing.T) {
+	c, err := NewConnectorWithConfig(``, Config{}, WithSavepoints())
+	require.NoError(t, err)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (i INTEGER, v INTEGER); INSERT INTO t VALUES (1, 0)`)
+
+	conn := openConnWrapper(t, db, context.Background())
+	defer closeConnWrapper(t, conn)
+
+	tx, err := conn.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	_, err = conn.ExecContext(context.Background(), `UPDATE t SET v = 1 WHERE i < 10`)
+	require.NoError(t, err)
+	require.NoError(t, Savepoint(conn, "a"))
+
+	// Another connection changes the rows the replayed UPDATE reads.
+	_, err = db.Exec(`INSERT INTO t VALUES (2, 0)`)
+	require.NoError(t, err)
+
+	err = RollbackTo(conn, "a")
+	require.ErrorIs(t, err, errSavepointReplay)
+	require.ErrorContains(t, err, replayRowsChangedErrMsg)
+
+	// The partially replayed transaction is aborted.
+	_, err = conn.ExecContext(context.Background(), `INSERT INTO t VALUES (3, 0)`)
+	require.ErrorContains(t, err, "aborted")
+	require.NoError(t, tx.Rollback())
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM t WHERE v = 1`).Scan(&count))
+	require.Equal(t, 0, count)
+}
+
+func TestNonReplayableFunc(t *testing.T) {
+	require.Equal(t, "", nonReplayableFunc(`INSERT INTO t VALUES (1, 'random()')`))
+	require.Equal(t, "", nonReplayableFunc(`SELECT t.random(), now FROM t /* now() */`))
+	require.Equal(t, "now", nonReplayableFunc(`INSERT INTO t SELECT now()`))
+	require.Equal(t, "current_timestamp", nonReplayableFunc(`INSERT INTO t SELECT CURRENT_TIMESTAMP`))
+	require.Equal(t, "read_csv", nonReplayableFunc(`INSERT INTO t FROM read_csv('f.csv')`))
+	require.Equal(t, "uuid", nonReplayableFunc(stmtText(`INSERT INTO t VALUES (1); INSERT INTO t VALUES (uuid())`, 1)))
+	require.Equal(t, "", nonReplayableFunc(stmtText(`INSERT INTO t VALUES (1); INSERT INTO t VALUES (uuid())`, 0)))
+}
+
+func TestRunInTx(t *testing.T) {
+	db := openDbWrapper(t, ``)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE counter (k INTEGER PRIMARY KEY, v INTEGER)`)
+
+	t.Run("retry", func(t *testing.T) {
+		var attempts []int
+		opts := RetryOptions{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			OnRetry: func(attempt int, err error, backoff time.Duration) {
+				require.ErrorContains(t, err, "conflict")
+				require.LessOrEqual(t, backoff, 2*time.Millisecond)
+				attempts = append(attempts, attempt)
+			},
+		}
+
+		calls := 0
+		err := RunInTx(context.Background(), db, opts, func(ctx context.Context, tx *sql.Tx) error {
+			calls++
+			if _, err := tx.ExecContext(ctx, `INSERT INTO counter VALUES (0, ?)`, calls); err != nil {
+				return err
+			}
+			if calls < 3 {
+				return &Error{Type: ErrorTypeTransaction, Msg: "TransactionContext Error: conflict"}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, []int{1, 2}, attempts)
+
+		// Only the last attempt committed.
+		var v int
+		require.NoError(t, db.QueryRow(`SELECT v FROM counter WHERE k = 0`).Scan(&v))
+		require.Equal(t, 3, v)
+
+		// RunInTx gives up after MaxAttempts.
+		calls = 0
+		err = RunInTx(context.Background(), db, opts, func(ctx context.Context, tx *sql.Tx) error {
+			calls++
+			return &Error{Type: ErrorTypeTransaction, Msg: "TransactionContext Error: conflict"}
+		})
+		require.Error(t, err)
+		require.Equal(t, 3, calls)
+
+		// RunInTx does not retry other errors.
+		calls = 0
+		err = RunInTx(context.Background(), db, opts, func(ctx context.Context, tx *sql.Tx) error {
+			calls++
+			_, err := tx.ExecContext(ctx, `SELECT * FROM missing`)
+			return err
+		})
+		require.Error(t, err)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("concurrent upserts", func(t *testing.T) {
+		const workers, increments = 4, 10
+		opts := RetryOptions{
+			MaxAttempts:    1000,
+			InitialBackoff: time.Millisecond,
+			// Concurrent inserts of the same new key fail with a constraint error.
+			ShouldRetry: func(err error) bool {
+				var duckdbErr *Error
+				return errors.As(err, &duckdbErr) &&
+					(duckdbErr.Type == ErrorTypeTransaction || duckdbErr.Type == ErrorTypeConstraint)
+			},
+		}
+
+		var wg sync.WaitGroup
+		errCh := make(chan error, workers*increments)
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for range increments {
+					errCh <- RunInTx(context.Background(), db, opts, func(ctx context.Context, tx *sql.Tx) error {
+						_, err := tx.ExecContext(ctx, `INSERT INTO counter VALUES (1, 1) ON CONFLICT DO UPDATE SET v = v + 1`)
+						return err
+					})
+				}
+			}()
+		}
+		wg.Wait()
+		close(errCh)
+		for err := range errCh {
+			require.NoError(t, err)
+		}
+
+		var v int
+		require.NoError(t, db.QueryRow(`SELECT v FROM counter WHERE k = 1`).Scan(&v))
+		require.Equal(t, workers*increments, v)
+	})
+
+	t.Run("context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		opts := RetryOptions{InitialBackoff: time.Hour, OnRetry: func(int, error, time.Duration) {
+			cancel()
+		}}
+		err := RunInTx(ctx, db, opts, func(ctx context.Context, tx *sql.Tx) error {
+			return &Error{Type: ErrorTypeTransaction, Msg: "TransactionContext Error: conflict"}
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestTxCallbacks(t *testing.T) {
+	db := openDbWrapper(t, ``)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE t (i INTEGER PRIMARY KEY)`)
+
+	t.Run("context", func(t *testing.T) {
+		var events []string
+		callbacks := &TxCallbacks{}
+		callbacks.OnCommit(func(err error) {
+			require.NoError(t, err)
+			events = append(events, "commit")
+		})
+		callbacks.OnRollback(func(err error) {
+			events = append(events, "rollback")
+		})
+
+		tx, err := db.BeginTx(WithTxCallbacks(context.Background(), callbacks), nil)
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO t VALUES (1)`)
+		require.NoError(t, err)
+
+		// Callbacks registered during the transaction also run.
+		callbacks.OnCommit(func(err error) {
+			events = append(events, "commit 2")
+		})
+		require.NoError(t, tx.Commit())
+		require.Equal(t, []string{"commit", "commit 2"}, events)
+	})
+
+	t.Run("conn", func(t *testing.T) {
+		conn := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, conn)
+
+		// Callbacks require a transaction.
+		require.ErrorIs(t, OnCommit(conn, func(error) {}), errNoTx)
+
+		tx, err := conn.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		var rolledBack bool
+		require.NoError(t, OnRollback(conn, func(err error) {
+			require.NoError(t, err)
+			rolledBack = true
+		}))
+		require.NoError(t, tx.Rollback())
+		require.True(t, rolledBack)
+
+		// A failed commit passes its error to the callbacks.
+		other := openConnWrapper(t, db, context.Background())
+		defer closeConnWrapper(t, other)
+		otherTx, err := other.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		_, err = otherTx.Exec(`INSERT INTO t VALUES (2)`)
+		require.NoError(t, err)
+
+		tx, err = conn.BeginTx(context.Background(), nil)
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO t VALUES (2)`)
+		require.NoError(t, err)
+		var commitErr error
+		require.NoError(t, OnCommit(conn, func(err error) {
+			commitErr = err
+		}))
+
+		require.NoError(t, otherTx.Commit())
+		err = tx.Commit()
+		require.Error(t, err)
+		require.Equal(t, err, commitErr)
+	})
+}
@@ -76,13 +76,89 @@ type typeInfo struct {
 type TypeInfo interface {
 	// InternalType returns the Type.
 	InternalType() Type
+	// Details returns the details of DECIMAL, ENUM, LIST, STRUCT, MAP, ARRAY, and UNION types.
+	// It returns nil for all other types.
+	Details() TypeDetails
 	logicalType() mapping.LogicalType
 }
 
+// TypeDetails is an interface for the details of a TypeInfo.
+// Its implementations are DecimalDetails, EnumDetails, ListDetails, StructDetails, MapDetails, ArrayDetails, and UnionDetails.
+type TypeDetails interface {
+	isTypeDetails()
+}
+
+// DecimalDetails contains the width and scale of a DECIMAL type.
+type DecimalDetails struct {
+	Width uint8
+	Scale uint8
+}
+
+// EnumDetails contains the dictionary values of an ENUM type.
+type EnumDetails struct {
+	Values []string
+}
+
+// ListDetails contains the type information of the elements of a LIST type.
+type ListDetails struct {
+	Child TypeInfo
+}
+
+// StructDetails contains the entries of a STRUCT type.
+type StructDetails struct {
+	Entries []StructEntry
+}
+
+// MapDetails contains the type information of the keys and values of a MAP type.
+type MapDetails struct {
+	Key   TypeInfo
+	Value TypeInfo
+}
+
+// ArrayDetails contains the type information of the elements, and the fixed size of an ARRAY type.
+type ArrayDetails struct {
+	Child TypeInfo
+	Size  uint64
+}
+
+// UnionDetails contains the member types and names of a UNION type.
+type UnionDetails struct {
+	Types []TypeInfo
+	Names []string
+}
+
+func (*DecimalDetails) isTypeDetails() {}
+func (*EnumDetails) isTypeDetails()    {}
+func (*ListDetails) isTypeDetails()    {}
+func (*StructDetails) isTypeDetails()  {}
+func (*MapDetails) isTypeDetails()     {}
+func (*ArrayDetails) isTypeDetails()   {}
+func (*UnionDetails) isTypeDetails()   {}
+
 func (info *typeInfo) InternalType() Type {
 	return info.Type
 }
 
+func (info *typeInfo) Details() TypeDetails {
+	switch info.Type {
+	case TYPE_DECIMAL:
+		return &DecimalDetails{Width: info.decimalWidth, Scale: info.decimalScale}
+	case TYPE_ENUM:
+		return &EnumDetails{Values: append([]string{}, info.names...)}
+	case TYPE_LIST:
+		return &ListDetails{Child: info.types[0]}
+	case TYPE_STRUCT:
+		return &StructDetails{Entries: append([]StructEntry{}, info.structEntries...)}
+	case TYPE_MAP:
+		return &MapDetails{Key: info.types[0], Value: info.types[1]}
+	case TYPE_ARRAY:
+		return &ArrayDetails{Child: info.types[0], Size: uint64(info.arrayLength)}
+	case TYPE_UNION:
+		return &UnionDetails{Types: append([]TypeInfo{}, info.types...), Names: append([]string{}, info.names...)}
+	}
+	return nil
+}
+
 // NewTypeInfo returns type information for DuckDB's primitive types.
 // It returns the TypeInfo, if the Type parameter is a valid primitive type.
 // Else, it returns nil, and an error.
@@ -313,7 +389,8 @@ func (info *typeInfo) logicalType() mapping.LogicalType {
 	case TYPE_UNION:
 		return info.logicalUnionType()
 	}
-	return mapping.LogicalType{}
+	// The constructors do not create other types, but newTypeInfoFromLogicalType does.
+	return mapping.CreateLogicalType(info.Type)
 }
 
 func (info *typeInfo) logicalListType() mapping.LogicalType {
@@ -357,6 +434,59 @@ func (info *typeInfo) logicalUnionType() mapping.LogicalType {
 	return mapping.CreateUnionType(types, info.names)
 }
 
+// newTypeInfoFromLogicalType returns the type information of a logical type.
+// Unlike NewTypeInfo, it does not reject types that are not supported as TypeInfo input,
+// so that it can describe any type, e.g., the TYPE_INVALID type of an unresolved parameter.
+func newTypeInfoFromLogicalType(lt mapping.LogicalType) TypeInfo {
+	t := mapping.GetTypeId(lt)
+	info := &typeInfo{baseTypeInfo: baseTypeInfo{Type: t}}
+
+	switch t {
+	case TYPE_DECIMAL:
+		info.decimalWidth = mapping.DecimalWidth(lt)
+		info.decimalScale = mapping.DecimalScale(lt)
+	case TYPE_ENUM:
+		size := mapping.EnumDictionarySize(lt)
+		for i := uint32(0); i < size; i++ {
+			info.names = append(info.names, mapping.EnumDictionaryValue(lt, mapping.IdxT(i)))
+		}
+	case TYPE_LIST:
+		child := mapping.ListTypeChildType(lt)
+		defer mapping.DestroyLogicalType(&child)
+		info.types = []TypeInfo{newTypeInfoFromLogicalType(child)}
+	case TYPE_STRUCT:
+		count := mapping.StructTypeChildCount(lt)
+		for i := mapping.IdxT(0); i < count; i++ {
+			child := mapping.StructTypeChildType(lt, i)
+			info.structEntries = append(info.structEntries, &structEntry{
+				TypeInfo: newTypeInfoFromLogicalType(child),
+				name:     mapping.StructTypeChildName(lt, i),
+			})
+			mapping.DestroyLogicalType(&child)
+		}
+	case TYPE_MAP:
+		key := mapping.MapTypeKeyType(lt)
+		defer mapping.DestroyLogicalType(&key)
+		value := mapping.MapTypeValueType(lt)
+		defer mapping.DestroyLogicalType(&value)
+		info.types = []TypeInfo{newTypeInfoFromLogicalType(key), newTypeInfoFromLogicalType(value)}
+	case TYPE_ARRAY:
+		child := mapping.ArrayTypeChildType(lt)
+		defer mapping.DestroyLogicalType(&child)
+		info.types = []TypeInfo{newTypeInfoFromLogicalType(child)}
+		info.arrayLength = mapping.ArrayTypeArraySize(lt)
+	case TYPE_UNION:
+		count := mapping.UnionTypeMemberCount(lt)
+		for i := mapping.IdxT(0); i < count; i++ {
+			member := mapping.UnionTypeMemberType(lt, i)
+			info.types = append(info.types, newTypeInfoFromLogicalType(member))
+			info.names = append(info.names, mapping.UnionTypeMemberName(lt, i))
+			mapping.DestroyLogicalType(&member)
+		}
+	}
+	return info
+}
+
 func funcName(i interface{}) string {
 	return runtime.FuncForPC(reflect.ValueOf(i).Pointer()).Name()
 }
@@ -0,0 +1,296 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// WriteQueueOptions configures a WriteQueue.
+type WriteQueueOptions struct {
+	// MaxBatchSize is the maximum number of requests per transaction. It defaults to 100.
+	MaxBatchSize int
+	// MaxLatency is the maximum time the queue waits for more requests,
+	// after receiving the first request of a batch. It defaults to 10ms.
+	MaxLatency time.Duration
+}
+
+// WriteQueue serializes writes from many goroutines onto a single dedicated connection.
+// It batches the requests into shared transactions, so that they do not conflict with each other.
+// A failing request does not affect the other requests of its batch:
+// the queue rolls back the transaction, and retries the batch without the failing request.
+type WriteQueue struct {
+	opts     WriteQueueOptions
+	conn     *Conn
+	requests chan *writeRequest
+	done     chan struct{}
+
+	// mu protects closed and sending on requests.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// writeRequest is a statement or an appender batch of a WriteQueue.
+type writeRequest struct {
+	// The statement and its arguments.
+	query string
+	args  []driver.NamedValue
+	// True, if the request is an appender batch of rows to append to schema.table.
+	appender bool
+	schema   string
+	table    string
+	rows     [][]driver.Value
+
+	// mu protects started and canceled.
+	mu       sync.Mutex
+	started  bool
+	canceled bool
+
+	res  driver.Result
+	err  error
+	done chan struct{}
+}
+
+// NewWriteQueue opens a dedicated connection and returns a WriteQueue executing its requests on the connection.
+// Close the WriteQueue to close its connection.
+func (c *Connector) NewWriteQueue(opts WriteQueueOptions) (*WriteQueue, error) {
+	if opts.MaxBatchSize <= 0 {
+		opts.MaxBatchSize = 100
+	}
+	if opts.MaxLatency <= 0 {
+		opts.MaxLatency = 10 * time.Millisecond
+	}
+
+	conn, err := c.Connect(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	q := &WriteQueue{
+		opts:     opts,
+		conn:     conn.(*Conn),
+		requests: make(chan *writeRequest, opts.MaxBatchSize),
+		done:     make(chan struct{}),
+	}
+	go q.run()
+	return q, nil
+}
+
+// Exec queues a statement that doesn't return rows, such as an INSERT or UPDATE,
+// and returns its result after its transaction commits.
+// If ctx is done before the queue executes the statement, Exec returns the context's error,
+// and the queue does not execute the statement.
+func (q *WriteQueue) Exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	namedArgs, err := q.namedArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &writeRequest{query: query, args: namedArgs}
+	if err = q.submit(ctx, req); err != nil {
+		return nil, err
+	}
+	return req.res, nil
+}
+
+// Append queues a batch of rows to append to the table schema.table,
+// and returns after its transaction commits.
+// If ctx is done before the queue appends the rows, Append returns the context's error,
+// and the queue does not append the rows.
+func (q *WriteQueue) Append(ctx context.Context, schema, table string, rows [][]driver.Value) error {
+	req := &writeRequest{appender: true, schema: schema, table: table, rows: rows}
+	return q.submit(ctx, req)
+}
+
+// Close stops accepting new requests, waits for the queued requests to finish,
+// and closes the connection of the WriteQueue.
+func (q *WriteQueue) Close() error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	close(q.requests)
+	q.mu.Unlock()
+
+	<-q.done
+	return q.conn.Close()
+}
+
+// namedArgs converts the arguments of a statement like database/sql.
+func (q *WriteQueue) namedArgs(args []any) ([]driver.NamedValue, error) {
+	namedArgs := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		nv := driver.NamedValue{Ordinal: i + 1, Value: arg}
+		if namedArg, ok := arg.(sql.NamedArg); ok {
+			nv.Name = namedArg.Name
+			nv.Value = namedArg.Value
+		}
+
+		if err := q.conn.convertArg(&nv); err != nil {
+			return nil, err
+		}
+		namedArgs[i] = nv
+	}
+	return namedArgs, nil
+}
+
+// submit queues a request and waits for its result.
+func (q *WriteQueue) submit(ctx context.Context, req *writeRequest) error {
+	req.done = make(chan struct{})
+
+	q.mu.RLock()
+	if q.closed {
+		q.mu.RUnlock()
+		return errWriteQueueClosed
+	}
+	select {
+	case q.requests <- req:
+		q.mu.RUnlock()
+	case <-ctx.Done():
+		q.mu.RUnlock()
+		return ctx.Err()
+	}
+
+	select {
+	case <-req.done:
+		return req.err
+	case <-ctx.Done():
+		if req.cancel() {
+			return ctx.Err()
+		}
+		// The queue is already executing the request.
+		<-req.done
+		return req.err
+	}
+}
+
+// cancel cancels the request, if the queue did not start executing it.
+func (req *writeRequest) cancel() bool {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	if req.started {
+		return false
+	}
+	req.canceled = true
+	return true
+}
+
+// start marks the request as started, if it is not canceled.
+func (req *writeRequest) start() bool {
+	req.mu.Lock()
+	defer req.mu.Unlock()
+	if req.canceled {
+		return false
+	}
+	req.started = true
+	return true
+}
+
+func (req *writeRequest) finish(err error) {
+	if err != nil {
+		req.res = nil
+	}
+	req.err = err
+	close(req.done)
+}
+
+// run executes the queued requests in batches until the queue is closed.
+func (q *WriteQueue) run() {
+	defer close(q.done)
+
+	for req := range q.requests {
+		batch := []*writeRequest{req}
+
+		timer := time.NewTimer(q.opts.MaxLatency)
+	collect:
+		for len(batch) < q.opts.MaxBatchSize {
+			select {
+			case req, ok := <-q.requests:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, req)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+
+		q.execBatch(batch)
+	}
+}
+
+// execBatch executes a batch of requests in a transaction.
+// If a request fails, it retries the remaining requests in a new transaction.
+func (q *WriteQueue) execBatch(batch []*writeRequest) {
+	batch = slices.DeleteFunc(batch, func(req *writeRequest) bool {
+		return !req.start()
+	})
+
+	for len(batch) != 0 {
+		failed, err := q.tryBatch(batch)
+		if err == nil {
+			for _, req := range batch {
+				req.finish(nil)
+			}
+			return
+		}
+
+		if failed < 0 {
+			// Beginning or committing the transaction failed.
+			for _, req := range batch {
+				req.finish(err)
+			}
+			return
+		}
+		batch[failed].finish(err)
+		batch = slices.Delete(batch, failed, failed+1)
+	}
+}
+
+// tryBatch executes a batch of requests in a transaction.
+// On failure, it returns the index of the failed request, or -1, if the transaction failed.
+func (q *WriteQueue) tryBatch(batch []*writeRequest) (int, error) {
+	ctx := context.Background()
+	tx, err := q.conn.BeginTx(ctx, driver.TxOptions{})
+	if err != nil {
+		return -1, err
+	}
+
+	for i, req := range batch {
+		if err = q.exec(ctx, req); err != nil {
+			if errRollback := tx.Rollback(); errRollback != nil {
+				err = errors.Join(err, errRollback)
+			}
+			return i, err
+		}
+	}
+
+	return -1, tx.Commit()
+}
+
+// exec executes a single request.
+func (q *WriteQueue) exec(ctx context.Context, req *writeRequest) error {
+	if !req.appender {
+		var err error
+		req.res, err = q.conn.ExecContext(ctx, req.query, req.args)
+		return err
+	}
+
+	a, err := NewAppenderFromConn(q.conn, req.schema, req.table)
+	if err != nil {
+		return err
+	}
+	for _, row := range req.rows {
+		if err = a.AppendRow(row...); err != nil {
+			return errors.Join(err, a.Close())
+		}
+	}
+	return a.Close()
+}
@@ -0,0 +1,84 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteQueue(t *testing.T) {
+	c := newConnectorWrapper(t, ``, nil)
+	db := sql.OpenDB(c)
+	defer closeDbWrapper(t, db)
+	createTable(t, db, `CREATE TABLE counter (k INTEGER PRIMARY KEY, v INTEGER)`)
+	createTable(t, db, `CREATE TABLE events (id INTEGER, name VARCHAR)`)
+
+	q, err := c.NewWriteQueue(WriteQueueOptions{MaxBatchSize: 16, MaxLatency: time.Millisecond})
+	require.NoError(t, err)
+
+	// Concurrent upserts do not conflict.
+	const workers, increments = 8, 20
+	var wg sync.WaitGroup
+	errCh := make(chan error, 2*workers*increments)
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range increments {
+				_, err := q.Exec(context.Background(),
+					`INSERT INTO counter VALUES (1, 1) ON CONFLICT DO UPDATE SET v = v + 1`)
+				errCh <- err
+
+				errCh <- q.Append(context.Background(), "", "events", [][]driver.Value{
+					{int32(w*increments + i), "event"},
+				})
+			}
+		}()
+	}
+
+	// A failing request does not affect the other requests of its batch.
+	_, err = q.Exec(context.Background(), `INSERT INTO counter VALUES (?, ?)`, "not a number", 1)
+	require.Error(t, err)
+	err = q.Append(context.Background(), "", "missing", [][]driver.Value{{1}})
+	require.Error(t, err)
+
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		require.NoError(t, err)
+	}
+
+	// Named arguments.
+	res, err := q.Exec(context.Background(), `INSERT INTO counter VALUES ($k, $v)`, sql.Named("k", 2), sql.Named("v", 42))
+	require.NoError(t, err)
+	n, err := res.RowsAffected()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	var v, events int
+	require.NoError(t, db.QueryRow(`SELECT v FROM counter WHERE k = 1`).Scan(&v))
+	require.Equal(t, workers*increments, v)
+	require.NoError(t, db.QueryRow(`SELECT v FROM counter WHERE k = 2`).Scan(&v))
+	require.Equal(t, 42, v)
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM events`).Scan(&events))
+	require.Equal(t, workers*increments, events)
+
+	// Canceled requests are not executed.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.Exec(ctx, `INSERT INTO counter VALUES (3, 3)`)
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, q.Close())
+	require.NoError(t, q.Close())
+	_, err = q.Exec(context.Background(), `INSERT INTO counter VALUES (3, 3)`)
+	require.ErrorIs(t, err, errWriteQueueClosed)
+
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM counter WHERE k = 3`).Scan(&v))
+	require.Equal(t, 0, v)
+}